@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeGRPCError trả lỗi của backend gRPC với HTTP status tương ứng mã gRPC,
+// kèm Retry-After khi backend gợi ý thời gian chờ (RetryInfo).
+// Giữ đồng bộ với worker.HTTPStatus trong go-services.
+func writeGRPCError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	body := gin.H{"error": st.Message(), "code": st.Code().String()}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.RetryInfo:
+			secs := math.Ceil(d.GetRetryDelay().AsDuration().Seconds())
+			c.Header("Retry-After", strconv.Itoa(int(secs)))
+		case *errdetails.ErrorInfo:
+			body["reason"] = d.GetReason()
+		}
+	}
+	c.JSON(httpStatus(st.Code()), body)
+}
+
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -6,13 +6,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"github/shieldx-bot/gateway/pb"
+	"github/shieldx-bot/laminar/config"
+	"github/shieldx-bot/laminar/healthcheck"
+	"github/shieldx-bot/laminar/logging"
+	"github/shieldx-bot/laminar/tracing"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq" // Driver postgres
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -21,25 +32,57 @@ import (
 var testHTTP3SingleFlight singleflight.Group
 var queryCache *ristretto.Cache
 
+// sfResult là kết quả singleflight dùng chung cho leader và các follower.
+type sfResult struct {
+	resp *pb.TestHTTP3Response
+	// leaderSpan là span singleflight của request đã thực sự gọi backend
+	leaderSpan trace.SpanContext
+}
+
 func main() {
+	// Cấu hình dùng chung với go-services: mặc định < file (-config) < LAMINAR_* < flag
+	cfg, err := config.Load("gateway", os.Args[1:])
+	if err != nil {
+		slog.Error("invalid config", slog.Any("error", err))
+		os.Exit(2)
+	}
+
+	// Logger slog dùng chung với go-services; level đổi lại được bằng SIGHUP
+	logger, err := logging.New(cfg.Log, "laminar-http-gateway")
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
+	go reloadLogLevelOnSIGHUP(logger)
+
+	// Tracing: nhận traceparent từ client HTTP, truyền tiếp qua metadata gRPC
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "laminar-http-gateway")
+	if err != nil {
+		panic(err)
+	}
+	defer shutdownTracing(context.Background())
 
-	router := gin.Default()
+	// Access log qua slog (2xx được lấy mẫu), sau tracing để mang trace id
+	router := gin.New()
+	router.Use(gin.Recovery(), tracing.Middleware(), logging.GinLogger(logger, logging.NewSampler(cfg.Log.SuccessSample)))
 
 	cache, err := ristretto.NewCache(&ristretto.Config{
 		NumCounters: 1e7,
-		MaxCost:     1 << 30,
+		MaxCost:     cfg.Backend.CacheMaxCost,
 		BufferItems: 64,
+		// Bật để export hit/miss ở /metrics
+		Metrics: true,
 	})
 	if err != nil {
 		panic(fmt.Errorf("failed to create ristretto cache: %w", err))
 	}
 	queryCache = cache
+	metrics := newGatewayMetrics(cache)
 
-	grpcAddr := os.Getenv("LAMINAR_GRPC_ADDR")
-	if grpcAddr == "" {
-		grpcAddr = "34.87.152.48:50051"
-	}
-	grpcConn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	grpcAddr := cfg.Backend.Addr
+	grpcConn, err := grpc.Dial(grpcAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
 	if err != nil {
 		panic(fmt.Errorf("dial %s: %w", grpcAddr, err))
 	}
@@ -52,13 +95,27 @@ func main() {
 		})
 	})
 
+	// /healthz: process còn sống; /readyz: backend gRPC báo SERVING, tức là
+	// gọi được và Postgres phía sau nó đang trả lời ping
+	readiness := healthcheck.NewReadiness(time.Duration(cfg.Health.Timeout))
+	readiness.Add("backend", healthcheck.GRPC(grpcConn, pb.LaminarGateway_ServiceDesc.ServiceName))
+	router.GET("/healthz", healthcheck.Liveness())
+	router.GET("/readyz", readiness.Handler())
+
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metrics.reg, promhttp.HandlerOpts{})))
+
 	// Fast endpoint for QUIC multiplexing tests (small response)
 	router.GET("/fast", func(c *gin.Context) {
 		c.Header("Cache-Control", "no-store")
 		c.JSON(http.StatusOK, gin.H{"ok": true})
 	})
+	// So sánh với service Go HTTP thường (cấu hình ở backend.ping_url / backend.naive_url)
 	router.GET("/ping-service-go", func(c *gin.Context) {
-		fetchURL := "http://34.87.152.48:8081/api/ping" // Thay đổi URL theo yêu cầu
+		fetchURL := cfg.Backend.PingURL
+		if fetchURL == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backend.ping_url is not configured"})
+			return
+		}
 		resp, err := http.Get(fetchURL)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch from external host"})
@@ -85,7 +142,11 @@ func main() {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		addr := "http://34.87.152.48:8081/api/naive"
+		addr := cfg.Backend.NaiveURL
+		if addr == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backend.naive_url is not configured"})
+			return
+		}
 		payload := map[string]string{
 			"QueryId":  jsonReq.QueryId,
 			"QuerySQL": jsonReq.QuerySQL,
@@ -115,19 +176,34 @@ func main() {
 	// POST query endpoint (good for load tests; avoids any accidental intermediary caching)
 	router.POST("/TestHTTP3", func(c *gin.Context) {
 		var jsonReq struct {
-			QueryId  string `json:"QueryId"`
-			QuerySQL string `json:"QuerySQL"`
-			Payload  string `json:"Payload"`
+			QueryId       string            `json:"QueryId"`
+			QuerySQL      string            `json:"QuerySQL"`
+			QueryName     string            `json:"QueryName"`
+			QueryTemplate string            `json:"QueryTemplate"`
+			Args          []json.RawMessage `json:"Args"`
+			Payload       string            `json:"Payload"`
 		}
 		if err := c.BindJSON(&jsonReq); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-
-		key := jsonReq.QuerySQL
-		if key == "" {
-			key = jsonReq.QueryId
+		args, err := parseArgs(jsonReq.Args)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		grpcReq := &pb.TestHTTP3Request{
+			QueryId:       jsonReq.QueryId,
+			QuerySQL:      jsonReq.QuerySQL,
+			QueryName:     jsonReq.QueryName,
+			QueryTemplate: jsonReq.QueryTemplate,
+			Args:          args,
+			Payload:       make([]byte, 10),
 		}
+		// query_id vào mọi dòng log của request, kể cả access log
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String(logging.KeyQueryID, jsonReq.QueryId)))
+
+		key := requestKey(grpcReq)
 
 		// 1) Local cache at gateway (hot responses)
 		if val, ok := queryCache.Get(key); ok {
@@ -137,55 +213,90 @@ func main() {
 					"QueryId":      jsonReq.QueryId,
 					"Records":      cachedResp.GetRecords(),
 					"ReceivedSize": cachedResp.GetReceivedSize(),
+					"Truncated":    cachedResp.GetTruncated(),
 				})
 				return
 			}
 		}
 
+		// fn chạy trên goroutine của leader; follower chỉ nhận kết quả dùng chung
+		sfCtx, sfSpan := tracing.Tracer().Start(c.Request.Context(), "singleflight TestHTTP3")
+		leader := false
 		resAny, err, _ := testHTTP3SingleFlight.Do(key, func() (interface{}, error) {
+			leader = true
+			// Follower gắn link tới span của leader, kể cả khi leader lỗi
+			res := &sfResult{leaderSpan: sfSpan.SpanContext()}
 			// Double-check cache inside singleflight to avoid duplicate work
 			if val, ok := queryCache.Get(key); ok {
 				if cachedResp, ok := val.(*pb.TestHTTP3Response); ok {
-					return cachedResp, nil
+					res.resp = cachedResp
+					return res, nil
 				}
 			}
 
-			ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
+			ctx, cancel := context.WithTimeout(sfCtx, time.Duration(cfg.Backend.Timeout))
 			defer cancel()
-			payload := make([]byte, 10)
-			resp, err := grpcClient.TestHTTP3(ctx, &pb.TestHTTP3Request{
-				QueryId:  jsonReq.QueryId,
-				QuerySQL: jsonReq.QuerySQL,
-				Payload:  payload,
-			})
+			start := time.Now()
+			resp, err := grpcClient.TestHTTP3(ctx, grpcReq)
+			metrics.observeBackend(start, err)
 			if err != nil {
-				return nil, err
+				return res, err
+			}
+			// 2) Store into gateway cache (backend.cache_ttl, mặc định 5s)
+			if ttl := time.Duration(cfg.Backend.CacheTTL); ttl > 0 {
+				queryCache.SetWithTTL(key, resp, 1, ttl)
 			}
-			// 2) Store into gateway cache (TTL 5s)
-			queryCache.SetWithTTL(key, resp, 1, 5*time.Second)
-			return resp, nil
+			res.resp = resp
+			return res, nil
 		})
+		metrics.observeSingleflight(leader)
+		res := resAny.(*sfResult)
+		sfSpan.SetAttributes(attribute.Bool("laminar.singleflight.leader", leader))
+		if !leader {
+			sfSpan.AddLink(trace.Link{SpanContext: res.leaderSpan})
+		}
+		if err != nil {
+			sfSpan.RecordError(err)
+		}
+		sfSpan.End()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("TestHTTP3: %v", err)})
+			writeGRPCError(c, err)
 			return
 		}
 
-		resp := resAny.(*pb.TestHTTP3Response)
+		resp := res.resp
 		// Preserve per-request QueryId even when coalesced.
 		c.JSON(http.StatusOK, gin.H{
 			"Status":       resp.GetStatus(),
 			"QueryId":      jsonReq.QueryId,
 			"Records":      resp.GetRecords(),
 			"ReceivedSize": resp.GetReceivedSize(),
+			"Truncated":    resp.GetTruncated(),
 		})
 
 	})
 
-	port := os.Getenv("LAMINAR_PROXY_PORT")
-	if port == "" {
-		port = "8081"
+	logger.Info("HTTP server listening", slog.String("addr", cfg.HTTP.Listen))
+
+	if err := router.Run(cfg.HTTP.Listen); err != nil { // listen and serve
+		logger.Error("failed to serve", slog.Any("error", err))
 	}
-	fmt.Printf("Starting server on :%s\n", port)
+}
 
-	router.Run("0.0.0.0:" + port) // listen and serve
+// reloadLogLevelOnSIGHUP đọc lại cấu hình mỗi lần nhận SIGHUP và áp dụng
+// log.level; cấu hình lỗi thì giữ level hiện tại.
+func reloadLogLevelOnSIGHUP(logger *slog.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		cfg, err := config.Load("gateway", os.Args[1:])
+		if err == nil {
+			err = logging.SetLevel(cfg.Log.Level)
+		}
+		if err != nil {
+			logger.Error("config reload failed, keeping current log level", slog.Any("error", err))
+			continue
+		}
+		logger.Info("log level reloaded", slog.String("level", logging.Level().String()))
+	}
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"google.golang.org/grpc/status"
+)
+
+// gatewayMetrics là metric của gateway HTTP/3, phục vụ ở /metrics.
+type gatewayMetrics struct {
+	reg *prometheus.Registry
+	// singleflight: leader gọi backend, follower dùng chung kết quả của leader
+	singleflight *prometheus.CounterVec
+	backend      *prometheus.HistogramVec
+}
+
+func newGatewayMetrics(cache *ristretto.Cache) *gatewayMetrics {
+	m := &gatewayMetrics{
+		reg: prometheus.NewRegistry(),
+		singleflight: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "laminar",
+			Subsystem: "gateway",
+			Name:      "singleflight_calls_total",
+			Help:      "TestHTTP3 calls through singleflight: leader ran the call, follower reused a concurrent leader's result.",
+		}, []string{"role"}),
+		backend: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "laminar",
+			Subsystem: "gateway",
+			Name:      "backend_seconds",
+			Help:      "Latency of TestHTTP3 calls to the gRPC backend by status code.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"code"}),
+	}
+	m.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.singleflight,
+		m.backend,
+		&cacheCollector{c: cache},
+	)
+	return m
+}
+
+func (m *gatewayMetrics) observeSingleflight(leader bool) {
+	if leader {
+		m.singleflight.WithLabelValues("leader").Inc()
+	} else {
+		m.singleflight.WithLabelValues("follower").Inc()
+	}
+}
+
+func (m *gatewayMetrics) observeBackend(start time.Time, err error) {
+	m.backend.WithLabelValues(status.Code(err).String()).Observe(time.Since(start).Seconds())
+}
+
+// cacheCollector đọc ristretto.Metrics lúc scrape (cache tạo với Metrics: true).
+type cacheCollector struct {
+	c *ristretto.Cache
+}
+
+var (
+	descCacheHits = prometheus.NewDesc("laminar_gateway_cache_hits_total",
+		"Response cache hits.", nil, nil)
+	descCacheMisses = prometheus.NewDesc("laminar_gateway_cache_misses_total",
+		"Response cache misses.", nil, nil)
+	descCacheRatio = prometheus.NewDesc("laminar_gateway_cache_hit_ratio",
+		"Response cache hits / lookups since start.", nil, nil)
+	descCacheCost = prometheus.NewDesc("laminar_gateway_cache_cost",
+		"Cost currently held by the response cache.", nil, nil)
+)
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descCacheHits
+	ch <- descCacheMisses
+	ch <- descCacheRatio
+	ch <- descCacheCost
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	m := c.c.Metrics
+	if m == nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(descCacheHits, prometheus.CounterValue, float64(m.Hits()))
+	ch <- prometheus.MustNewConstMetric(descCacheMisses, prometheus.CounterValue, float64(m.Misses()))
+	ch <- prometheus.MustNewConstMetric(descCacheRatio, prometheus.GaugeValue, m.Ratio())
+	ch <- prometheus.MustNewConstMetric(descCacheCost, prometheus.GaugeValue, float64(m.CostAdded()-m.CostEvicted()))
+}
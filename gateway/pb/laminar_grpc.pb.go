@@ -24,6 +24,8 @@ const (
 	LaminarGateway_PipelineProcess_FullMethodName   = "/laminar.LaminarGateway/PipelineProcess"
 	LaminarGateway_TestHTTP3_FullMethodName         = "/laminar.LaminarGateway/TestHTTP3"
 	LaminarGateway_PingPong_FullMethodName          = "/laminar.LaminarGateway/PingPong"
+	LaminarGateway_ResizeShards_FullMethodName      = "/laminar.LaminarGateway/ResizeShards"
+	LaminarGateway_UpdateTuning_FullMethodName      = "/laminar.LaminarGateway/UpdateTuning"
 )
 
 // LaminarGatewayClient is the client API for LaminarGateway service.
@@ -41,6 +43,12 @@ type LaminarGatewayClient interface {
 	PipelineProcess(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[WorkRequest, WorkResponse], error)
 	TestHTTP3(ctx context.Context, in *TestHTTP3Request, opts ...grpc.CallOption) (*TestHTTP3Response, error)
 	PingPong(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	// Admin: đổi số worker shard lúc runtime. Job đã nhận không bao giờ bị drop;
+	// shard bị bỏ xử lý nốt hàng đợi rồi mới dừng.
+	ResizeShards(ctx context.Context, in *ResizeShardsRequest, opts ...grpc.CallOption) (*ResizeShardsResponse, error)
+	// Admin: đổi tham số worker (watermark, ngưỡng inbox, steal, max queue age)
+	// lúc runtime. Chỉ field được đặt mới thay đổi; request rỗng trả về giá trị hiện tại.
+	UpdateTuning(ctx context.Context, in *UpdateTuningRequest, opts ...grpc.CallOption) (*UpdateTuningResponse, error)
 }
 
 type laminarGatewayClient struct {
@@ -113,6 +121,26 @@ func (c *laminarGatewayClient) PingPong(ctx context.Context, in *PingRequest, op
 	return out, nil
 }
 
+func (c *laminarGatewayClient) ResizeShards(ctx context.Context, in *ResizeShardsRequest, opts ...grpc.CallOption) (*ResizeShardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResizeShardsResponse)
+	err := c.cc.Invoke(ctx, LaminarGateway_ResizeShards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *laminarGatewayClient) UpdateTuning(ctx context.Context, in *UpdateTuningRequest, opts ...grpc.CallOption) (*UpdateTuningResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateTuningResponse)
+	err := c.cc.Invoke(ctx, LaminarGateway_UpdateTuning_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LaminarGatewayServer is the server API for LaminarGateway service.
 // All implementations must embed UnimplementedLaminarGatewayServer
 // for forward compatibility.
@@ -128,6 +156,12 @@ type LaminarGatewayServer interface {
 	PipelineProcess(grpc.BidiStreamingServer[WorkRequest, WorkResponse]) error
 	TestHTTP3(context.Context, *TestHTTP3Request) (*TestHTTP3Response, error)
 	PingPong(context.Context, *PingRequest) (*PingResponse, error)
+	// Admin: đổi số worker shard lúc runtime. Job đã nhận không bao giờ bị drop;
+	// shard bị bỏ xử lý nốt hàng đợi rồi mới dừng.
+	ResizeShards(context.Context, *ResizeShardsRequest) (*ResizeShardsResponse, error)
+	// Admin: đổi tham số worker (watermark, ngưỡng inbox, steal, max queue age)
+	// lúc runtime. Chỉ field được đặt mới thay đổi; request rỗng trả về giá trị hiện tại.
+	UpdateTuning(context.Context, *UpdateTuningRequest) (*UpdateTuningResponse, error)
 	mustEmbedUnimplementedLaminarGatewayServer()
 }
 
@@ -153,6 +187,12 @@ func (UnimplementedLaminarGatewayServer) TestHTTP3(context.Context, *TestHTTP3Re
 func (UnimplementedLaminarGatewayServer) PingPong(context.Context, *PingRequest) (*PingResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PingPong not implemented")
 }
+func (UnimplementedLaminarGatewayServer) ResizeShards(context.Context, *ResizeShardsRequest) (*ResizeShardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResizeShards not implemented")
+}
+func (UnimplementedLaminarGatewayServer) UpdateTuning(context.Context, *UpdateTuningRequest) (*UpdateTuningResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTuning not implemented")
+}
 func (UnimplementedLaminarGatewayServer) mustEmbedUnimplementedLaminarGatewayServer() {}
 func (UnimplementedLaminarGatewayServer) testEmbeddedByValue()                        {}
 
@@ -246,6 +286,42 @@ func _LaminarGateway_PingPong_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LaminarGateway_ResizeShards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizeShardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaminarGatewayServer).ResizeShards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaminarGateway_ResizeShards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaminarGatewayServer).ResizeShards(ctx, req.(*ResizeShardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LaminarGateway_UpdateTuning_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTuningRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LaminarGatewayServer).UpdateTuning(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LaminarGateway_UpdateTuning_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LaminarGatewayServer).UpdateTuning(ctx, req.(*UpdateTuningRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LaminarGateway_ServiceDesc is the grpc.ServiceDesc for LaminarGateway service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -265,6 +341,14 @@ var LaminarGateway_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PingPong",
 			Handler:    _LaminarGateway_PingPong_Handler,
 		},
+		{
+			MethodName: "ResizeShards",
+			Handler:    _LaminarGateway_ResizeShards_Handler,
+		},
+		{
+			MethodName: "UpdateTuning",
+			Handler:    _LaminarGateway_UpdateTuning_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,230 @@
+// Package client is the Go SDK for the Laminar gateway gRPC API. It wraps
+// pb.LaminarGatewayClient with per-call deadlines, retries with jittered
+// backoff that honor the server's RetryInfo hints, hedged requests for
+// idempotent queries, and helpers to decode query records into Go structs.
+package client
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// Mặc định của client.
+const (
+	DefaultTimeout = 3 * time.Second
+	// adminTokenKey khớp với metadata key mà gateway kiểm tra cho RPC quản trị.
+	adminTokenKey = "x-admin-token"
+)
+
+// Client gọi LaminarGateway. An toàn khi dùng đồng thời từ nhiều goroutine.
+type Client struct {
+	conn *grpc.ClientConn
+	rpc  pb.LaminarGatewayClient
+
+	timeout    time.Duration
+	retry      RetryPolicy
+	hedge      HedgePolicy
+	adminToken string
+}
+
+type options struct {
+	dialOpts   []grpc.DialOption
+	timeout    time.Duration
+	retry      RetryPolicy
+	hedge      HedgePolicy
+	adminToken string
+}
+
+// Option cấu hình Client khi khởi tạo.
+type Option func(*options)
+
+// WithDialOptions appends gRPC dial options, e.g. transport credentials.
+// Without credentials the client connects in plaintext.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOpts = append(o.dialOpts, opts...)
+	}
+}
+
+// WithTimeout sets the deadline applied to calls whose context has none.
+// Zero leaves such calls without a deadline.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithRetry replaces the default retry policy.
+func WithRetry(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = p
+	}
+}
+
+// WithHedging enables hedged requests for idempotent queries.
+func WithHedging(p HedgePolicy) Option {
+	return func(o *options) {
+		o.hedge = p
+	}
+}
+
+// WithAdminToken sets the token sent with admin RPCs such as ResizeShards
+// and UpdateTuning.
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.adminToken = token
+	}
+}
+
+// New connects to the gateway at addr. The connection is established lazily
+// on the first call.
+func New(addr string, opts ...Option) (*Client, error) {
+	o := options{
+		timeout: DefaultTimeout,
+		retry:   DefaultRetryPolicy,
+		// Credentials mặc định đặt trước để WithDialOptions ghi đè được
+		dialOpts: []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	conn, err := grpc.NewClient(addr, o.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:       conn,
+		rpc:        pb.NewLaminarGatewayClient(conn),
+		timeout:    o.timeout,
+		retry:      o.retry,
+		hedge:      o.hedge,
+		adminToken: o.adminToken,
+	}, nil
+}
+
+// Close closes the underlying connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Raw returns the generated client for RPCs the SDK does not wrap.
+func (c *Client) Raw() pb.LaminarGatewayClient {
+	return c.rpc
+}
+
+// callOptions là tuỳ chọn cho một lần gọi.
+type callOptions struct {
+	timeout    time.Duration
+	idempotent bool
+	noRetry    bool
+}
+
+// CallOption tuỳ chỉnh một lần gọi.
+type CallOption func(*callOptions)
+
+// Timeout overrides the client's default deadline for one call.
+func Timeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// Idempotent marks a query as safe to run more than once. Idempotent calls
+// are hedged when hedging is enabled and are retried on any retryable error,
+// not only on rejections that guarantee the query never ran.
+func Idempotent() CallOption {
+	return func(o *callOptions) {
+		o.idempotent = true
+	}
+}
+
+// NoRetry disables retries for one call.
+func NoRetry() CallOption {
+	return func(o *callOptions) {
+		o.noRetry = true
+	}
+}
+
+func (c *Client) callOptions(opts []CallOption) callOptions {
+	co := callOptions{timeout: c.timeout}
+	for _, opt := range opts {
+		opt(&co)
+	}
+	return co
+}
+
+// withDeadline áp timeout mặc định nếu ctx chưa có deadline.
+func withDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// Query runs a TestHTTP3 query.
+func (c *Client) Query(ctx context.Context, req *pb.TestHTTP3Request, opts ...CallOption) (*pb.TestHTTP3Response, error) {
+	co := c.callOptions(opts)
+	return invoke(ctx, c, co, func(ctx context.Context) (*pb.TestHTTP3Response, error) {
+		return c.rpc.TestHTTP3(ctx, req)
+	})
+}
+
+// QueryNamed runs a query registered on the server under name. Like Query it
+// is only retried when the server reports the query never ran; pass
+// Idempotent() for registry queries marked read_only to also retry other
+// transient errors and hedge them.
+func (c *Client) QueryNamed(ctx context.Context, name string, args []*pb.QueryArg, opts ...CallOption) (*pb.TestHTTP3Response, error) {
+	req := &pb.TestHTTP3Request{QueryName: name, Args: args}
+	return c.Query(ctx, req, opts...)
+}
+
+// ProcessSingle runs one DB-free WorkRequest.
+func (c *Client) ProcessSingle(ctx context.Context, req *pb.WorkRequest, opts ...CallOption) (*pb.WorkResponse, error) {
+	co := c.callOptions(opts)
+	return invoke(ctx, c, co, func(ctx context.Context) (*pb.WorkResponse, error) {
+		return c.rpc.ProcessSingle(ctx, req)
+	})
+}
+
+// Ping calls PingPong and returns the server's reply.
+func (c *Client) Ping(ctx context.Context, message string) (string, error) {
+	ctx, cancel := withDeadline(ctx, c.timeout)
+	defer cancel()
+	resp, err := c.rpc.PingPong(ctx, &pb.PingRequest{Message: message})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetMessage(), nil
+}
+
+// Subscribe opens a SubscribeToEvents stream for topic. The stream lives
+// until ctx is canceled; no default deadline is applied.
+func (c *Client) Subscribe(ctx context.Context, topic string) (grpc.ServerStreamingClient[pb.WorkResponse], error) {
+	return c.rpc.SubscribeToEvents(ctx, &pb.EventSubscription{Topic: topic})
+}
+
+// ResizeShards changes the server's worker shard count. Requires
+// WithAdminToken. It is never retried.
+func (c *Client) ResizeShards(ctx context.Context, shards int) (*pb.ResizeShardsResponse, error) {
+	ctx, cancel := withDeadline(ctx, c.timeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, adminTokenKey, c.adminToken)
+	return c.rpc.ResizeShards(ctx, &pb.ResizeShardsRequest{Shards: int32(shards)})
+}
+
+// UpdateTuning changes the worker tuning fields set in req and returns the
+// previous and current values; an empty request only reads them. Requires
+// WithAdminToken. It is never retried.
+func (c *Client) UpdateTuning(ctx context.Context, req *pb.UpdateTuningRequest) (*pb.UpdateTuningResponse, error) {
+	ctx, cancel := withDeadline(ctx, c.timeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, adminTokenKey, c.adminToken)
+	return c.rpc.UpdateTuning(ctx, req)
+}
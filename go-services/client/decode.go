@@ -0,0 +1,108 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// DecodeRecord decodes one query record into dst using encoding/json rules
+// and field tags. The server sends bigint values beyond 2^53 and numeric
+// columns as strings to keep precision; decode those into string fields or
+// tag numeric fields with `json:",string"`. Timestamps arrive as RFC 3339
+// strings and decode into time.Time.
+func DecodeRecord(rec *structpb.Struct, dst any) error {
+	b, err := protojson.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// DecodeRecords decodes every record of a query response into a T.
+func DecodeRecords[T any](records []*structpb.Struct) ([]T, error) {
+	out := make([]T, len(records))
+	for i, rec := range records {
+		if err := DecodeRecord(rec, &out[i]); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// Args converts Go values into typed query arguments for $1, $2, ...
+// Supported: nil, string, []byte, bool, all int and float kinds, time.Time.
+// Unsigned values above math.MaxInt64 are rejected.
+func Args(values ...any) ([]*pb.QueryArg, error) {
+	args := make([]*pb.QueryArg, len(values))
+	for i, v := range values {
+		a, err := arg(v)
+		if err != nil {
+			return nil, fmt.Errorf("arg $%d: %w", i+1, err)
+		}
+		args[i] = a
+	}
+	return args, nil
+}
+
+func arg(v any) (*pb.QueryArg, error) {
+	switch x := v.(type) {
+	case nil:
+		return &pb.QueryArg{Value: &pb.QueryArg_NullValue{NullValue: true}}, nil
+	case string:
+		return &pb.QueryArg{Value: &pb.QueryArg_StringValue{StringValue: x}}, nil
+	case []byte:
+		return &pb.QueryArg{Value: &pb.QueryArg_BytesValue{BytesValue: x}}, nil
+	case bool:
+		return &pb.QueryArg{Value: &pb.QueryArg_BoolValue{BoolValue: x}}, nil
+	case int:
+		return intArg(int64(x)), nil
+	case int8:
+		return intArg(int64(x)), nil
+	case int16:
+		return intArg(int64(x)), nil
+	case int32:
+		return intArg(int64(x)), nil
+	case int64:
+		return intArg(x), nil
+	case uint8:
+		return intArg(int64(x)), nil
+	case uint16:
+		return intArg(int64(x)), nil
+	case uint32:
+		return intArg(int64(x)), nil
+	case uint:
+		return uintArg(uint64(x))
+	case uint64:
+		return uintArg(x)
+	case uintptr:
+		return uintArg(uint64(x))
+	case float32:
+		return &pb.QueryArg{Value: &pb.QueryArg_DoubleValue{DoubleValue: float64(x)}}, nil
+	case float64:
+		return &pb.QueryArg{Value: &pb.QueryArg_DoubleValue{DoubleValue: x}}, nil
+	case time.Time:
+		return &pb.QueryArg{Value: &pb.QueryArg_TimestampValue{TimestampValue: timestamppb.New(x)}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+func intArg(n int64) *pb.QueryArg {
+	return &pb.QueryArg{Value: &pb.QueryArg_IntValue{IntValue: n}}
+}
+
+// uintArg từ chối giá trị vượt int64: Postgres không có kiểu số nguyên không dấu.
+func uintArg(n uint64) (*pb.QueryArg, error) {
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("%d overflows int64", n)
+	}
+	return intArg(int64(n)), nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+func TestArgs(t *testing.T) {
+	intv := func(n int64) *pb.QueryArg { return &pb.QueryArg{Value: &pb.QueryArg_IntValue{IntValue: n}} }
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		value   any
+		want    *pb.QueryArg
+		wantErr bool
+	}{
+		{"nil", nil, &pb.QueryArg{Value: &pb.QueryArg_NullValue{NullValue: true}}, false},
+		{"string", "a", &pb.QueryArg{Value: &pb.QueryArg_StringValue{StringValue: "a"}}, false},
+		{"bytes", []byte{1}, &pb.QueryArg{Value: &pb.QueryArg_BytesValue{BytesValue: []byte{1}}}, false},
+		{"bool", true, &pb.QueryArg{Value: &pb.QueryArg_BoolValue{BoolValue: true}}, false},
+		{"int8", int8(-8), intv(-8), false},
+		{"int64 min", int64(math.MinInt64), intv(math.MinInt64), false},
+		{"uint16", uint16(16), intv(16), false},
+		{"uint32 max", uint32(math.MaxUint32), intv(math.MaxUint32), false},
+		{"uint", uint(42), intv(42), false},
+		{"uintptr", uintptr(7), intv(7), false},
+		{"uint64 max int64", uint64(math.MaxInt64), intv(math.MaxInt64), false},
+		{"uint64 overflow", uint64(math.MaxInt64) + 1, nil, true},
+		{"float32", float32(0.5), &pb.QueryArg{Value: &pb.QueryArg_DoubleValue{DoubleValue: 0.5}}, false},
+		{"time", at, &pb.QueryArg{Value: &pb.QueryArg_TimestampValue{TimestampValue: timestamppb.New(at)}}, false},
+		{"unsupported", struct{}{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := Args(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Args(%v) = %v, want an error", tt.value, args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Args(%v): %v", tt.value, err)
+			}
+			if !proto.Equal(args[0], tt.want) {
+				t.Errorf("Args(%v) = %v, want %v", tt.value, args[0], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github/shieldx-bot/laminar/errinfo"
+)
+
+// RetryPolicy điều khiển việc thử lại với exponential backoff có jitter.
+type RetryPolicy struct {
+	// MaxAttempts gồm cả lần gọi đầu; <= 1 là không thử lại.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy thử tối đa 3 lần, backoff 50ms, 100ms, ... tối đa 2s.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+// HedgePolicy: nếu chưa có kết quả sau Delay, gửi thêm một bản sao của
+// request (tối đa MaxHedges bản), lấy kết quả thành công đầu tiên.
+// Chỉ áp dụng cho lời gọi Idempotent.
+type HedgePolicy struct {
+	Delay     time.Duration
+	MaxHedges int
+}
+
+// invoke chạy call với deadline, hedging và retry theo cấu hình của c.
+func invoke[T any](ctx context.Context, c *Client, co callOptions, call func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := withDeadline(ctx, co.timeout)
+	defer cancel()
+
+	attempts := c.retry.MaxAttempts
+	if co.noRetry || attempts < 1 {
+		attempts = 1
+	}
+
+	var zero T
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if !sleep(ctx, c.retry.backoff(attempt, lastErr)) {
+				return zero, lastErr
+			}
+		}
+
+		var resp T
+		var err error
+		if co.idempotent && c.hedge.MaxHedges > 0 {
+			resp, err = hedged(ctx, c.hedge, call)
+		} else {
+			resp, err = call(ctx)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if !retryable(err, co.idempotent) {
+			break
+		}
+	}
+	return zero, lastErr
+}
+
+// retryable: query không idempotent chỉ được thử lại khi server báo nó chưa
+// chạy; query idempotent thử lại với mọi lỗi tạm thời.
+func retryable(err error, idempotent bool) bool {
+	switch status.Code(err) {
+	case codes.ResourceExhausted, codes.Unavailable:
+		return idempotent || errinfo.NotExecuted(errorReason(err))
+	case codes.Aborted:
+		return idempotent
+	}
+	return false
+}
+
+// backoff trả về thời gian chờ trước lần thử thứ attempt (bắt đầu từ 1).
+// Full jitter trong [0, base); nếu server gợi ý RetryInfo thì chờ ít nhất
+// bằng gợi ý đó, cộng jitter để các client không cùng quay lại một lúc.
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	base := float64(p.InitialBackoff) * math.Pow(math.Max(p.Multiplier, 1), float64(attempt-1))
+	if p.MaxBackoff > 0 {
+		base = math.Min(base, float64(p.MaxBackoff))
+	}
+	var jitter time.Duration
+	if base >= 1 {
+		jitter = time.Duration(rand.Int64N(int64(base)))
+	}
+	if hint, ok := retryDelay(err); ok {
+		return hint + jitter/2
+	}
+	return jitter
+}
+
+// sleep chờ d, false nếu ctx hết hạn trước (hoặc sẽ hết hạn trong lúc chờ).
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// hedged gửi request, và gửi thêm bản sao sau mỗi p.Delay khi chưa có kết
+// quả, trả về kết quả thành công đầu tiên. Các bản sao còn lại bị huỷ.
+func hedged[T any](ctx context.Context, p HedgePolicy, call func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		resp T
+		err  error
+	}
+	results := make(chan result, p.MaxHedges+1)
+	launch := func() {
+		go func() {
+			resp, err := call(ctx)
+			results <- result{resp, err}
+		}()
+	}
+
+	launch()
+	sent, inFlight := 1, 1
+	timer := time.NewTimer(p.Delay)
+	defer timer.Stop()
+
+	var zero T
+	for {
+		select {
+		case r := <-results:
+			inFlight--
+			if r.err == nil {
+				return r.resp, nil
+			}
+			if inFlight == 0 {
+				return zero, r.err
+			}
+		case <-timer.C:
+			if sent <= p.MaxHedges {
+				launch()
+				sent++
+				inFlight++
+				timer.Reset(p.Delay)
+			}
+		case <-ctx.Done():
+			return zero, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func retryDelay(err error) (time.Duration, bool) {
+	for _, d := range status.Convert(err).Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			return ri.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+func errorReason(err error) string {
+	for _, d := range status.Convert(err).Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok && ei.GetDomain() == errinfo.Domain {
+			return ei.GetReason()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,268 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github/shieldx-bot/laminar/errinfo"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// statusErr tạo lỗi giống server: ErrorInfo với reason và, nếu retryAfter > 0, RetryInfo.
+func statusErr(code codes.Code, reason string, retryAfter time.Duration) error {
+	st := status.New(code, "test error")
+	if reason != "" {
+		st, _ = st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errinfo.Domain})
+	}
+	if retryAfter > 0 {
+		st, _ = st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+	return st.Err()
+}
+
+// fakeGateway trả lời TestHTTP3 bằng handle; call đếm từ 1.
+type fakeGateway struct {
+	pb.UnimplementedLaminarGatewayServer
+	calls  atomic.Int32
+	handle func(ctx context.Context, call int) (*pb.TestHTTP3Response, error)
+}
+
+func (g *fakeGateway) TestHTTP3(ctx context.Context, _ *pb.TestHTTP3Request) (*pb.TestHTTP3Response, error) {
+	return g.handle(ctx, int(g.calls.Add(1)))
+}
+
+// newTestClient chạy fakeGateway trên bufconn và trả về Client nối tới nó.
+func newTestClient(t *testing.T, handle func(context.Context, int) (*pb.TestHTTP3Response, error), opts ...Option) (*Client, *fakeGateway) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	g := &fakeGateway{handle: handle}
+	pb.RegisterLaminarGatewayServer(srv, g)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+	c, err := New("passthrough:///bufnet", append([]Option{WithDialOptions(dialer)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, g
+}
+
+// failThenOK trả err cho failures lần gọi đầu rồi thành công.
+func failThenOK(failures int, err error) func(context.Context, int) (*pb.TestHTTP3Response, error) {
+	return func(_ context.Context, call int) (*pb.TestHTTP3Response, error) {
+		if call <= failures {
+			return nil, err
+		}
+		return &pb.TestHTTP3Response{Status: "ok"}, nil
+	}
+}
+
+// fastRetry giữ backoff nhỏ để test chỉ phụ thuộc vào RetryInfo của server.
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		idempotent bool
+		want       bool
+	}{
+		{"queue full never ran", statusErr(codes.ResourceExhausted, errinfo.ShardQueueFull, 0), false, true},
+		{"concurrency limit never ran", statusErr(codes.ResourceExhausted, errinfo.ConcurrencyLimit, 0), false, true},
+		{"shutting down never ran", statusErr(codes.Unavailable, errinfo.ShuttingDown, 0), false, true},
+		{"expired in queue never ran", statusErr(codes.Unavailable, errinfo.ExpiredInQueue, 0), false, true},
+		{"dropped by policy never ran", statusErr(codes.Unavailable, errinfo.DroppedByPolicy, 0), false, true},
+		{"db unavailable may have run", statusErr(codes.Unavailable, errinfo.DBUnavailable, 0), false, false},
+		{"db unavailable idempotent", statusErr(codes.Unavailable, errinfo.DBUnavailable, 0), true, true},
+		{"unavailable without reason", status.Error(codes.Unavailable, "transport"), false, false},
+		{"unavailable without reason idempotent", status.Error(codes.Unavailable, "transport"), true, true},
+		{"conflict idempotent", statusErr(codes.Aborted, errinfo.Conflict, 0), true, true},
+		{"conflict not idempotent", statusErr(codes.Aborted, errinfo.Conflict, 0), false, false},
+		{"invalid query", statusErr(codes.InvalidArgument, errinfo.InvalidQuery, 0), true, false},
+		{"deadline", status.Error(codes.DeadlineExceeded, "late"), true, false},
+		{"plain error", errors.New("boom"), true, false},
+		{"foreign domain reason ignored", func() error {
+			st, _ := status.New(codes.Unavailable, "x").WithDetails(&errdetails.ErrorInfo{Reason: errinfo.ShardQueueFull, Domain: "other"})
+			return st.Err()
+		}(), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err, tt.idempotent); got != tt.want {
+				t.Errorf("retryable(%v, idempotent=%v) = %v, want %v", tt.err, tt.idempotent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryRetriesWhenNotExecuted(t *testing.T) {
+	c, g := newTestClient(t, failThenOK(2, statusErr(codes.ResourceExhausted, errinfo.ShardQueueFull, 0)), WithRetry(fastRetry))
+	resp, err := c.Query(context.Background(), &pb.TestHTTP3Request{QueryName: "q"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if resp.GetStatus() != "ok" || g.calls.Load() != 3 {
+		t.Fatalf("status=%q calls=%d, want ok after 3 calls", resp.GetStatus(), g.calls.Load())
+	}
+}
+
+func TestQueryNamedIsNotIdempotentByDefault(t *testing.T) {
+	mayHaveRun := statusErr(codes.Unavailable, errinfo.DBUnavailable, 0)
+
+	c, g := newTestClient(t, failThenOK(1, mayHaveRun), WithRetry(fastRetry))
+	if _, err := c.QueryNamed(context.Background(), "insert_user", nil); status.Code(err) != codes.Unavailable {
+		t.Fatalf("QueryNamed = %v, want the Unavailable error", err)
+	}
+	if n := g.calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1: a named query that may have run must not be retried", n)
+	}
+
+	c, g = newTestClient(t, failThenOK(1, mayHaveRun), WithRetry(fastRetry))
+	if _, err := c.QueryNamed(context.Background(), "user_by_id", nil, Idempotent()); err != nil {
+		t.Fatalf("QueryNamed(Idempotent): %v", err)
+	}
+	if n := g.calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2 with Idempotent()", n)
+	}
+}
+
+func TestNoRetry(t *testing.T) {
+	c, g := newTestClient(t, failThenOK(1, statusErr(codes.ResourceExhausted, errinfo.ShardQueueFull, 0)), WithRetry(fastRetry))
+	if _, err := c.Query(context.Background(), &pb.TestHTTP3Request{}, NoRetry()); err == nil {
+		t.Fatal("Query(NoRetry) succeeded, want the first error")
+	}
+	if n := g.calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
+
+func TestRetryHonorsRetryInfo(t *testing.T) {
+	const hint = 80 * time.Millisecond
+	c, g := newTestClient(t, failThenOK(1, statusErr(codes.ResourceExhausted, errinfo.ConcurrencyLimit, hint)), WithRetry(fastRetry))
+
+	start := time.Now()
+	if _, err := c.Query(context.Background(), &pb.TestHTTP3Request{}); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < hint {
+		t.Fatalf("retried after %v, want at least the server hint %v", elapsed, hint)
+	}
+	if n := g.calls.Load(); n != 2 {
+		t.Fatalf("calls = %d, want 2", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond, Multiplier: 2}
+	hinted := statusErr(codes.ResourceExhausted, errinfo.ShardQueueFull, time.Second)
+	for attempt := 1; attempt <= 5; attempt++ {
+		base := min(10*time.Millisecond<<(attempt-1), 40*time.Millisecond)
+		for i := 0; i < 50; i++ {
+			if d := p.backoff(attempt, nil); d < 0 || d >= base {
+				t.Fatalf("backoff(%d) = %v, want in [0, %v)", attempt, d, base)
+			}
+			// Có RetryInfo: chờ ít nhất bằng gợi ý, cộng tối đa nửa jitter
+			if d := p.backoff(attempt, hinted); d < time.Second || d >= time.Second+base/2 {
+				t.Fatalf("backoff(%d, hint 1s) = %v, want in [1s, %v)", attempt, d, time.Second+base/2)
+			}
+		}
+	}
+}
+
+func TestSleepStopsBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if sleep(ctx, time.Second) {
+		t.Fatal("sleep past the deadline returned true")
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("sleep took %v, want an immediate return when the wait outlives the deadline", elapsed)
+	}
+	if !sleep(ctx, time.Millisecond) {
+		t.Fatal("sleep within the deadline returned false")
+	}
+}
+
+func TestRetryGivesUpWhenHintOutlivesDeadline(t *testing.T) {
+	c, g := newTestClient(t, failThenOK(1, statusErr(codes.ResourceExhausted, errinfo.ShardQueueFull, time.Second)), WithRetry(fastRetry))
+
+	start := time.Now()
+	_, err := c.Query(context.Background(), &pb.TestHTTP3Request{}, Timeout(200*time.Millisecond))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Query = %v, want the server's ResourceExhausted error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("Query took %v, want to give up without waiting for the 1s hint", elapsed)
+	}
+	if n := g.calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
+
+func TestHedgedQuery(t *testing.T) {
+	firstCanceled := make(chan struct{})
+	slowFirst := func(ctx context.Context, call int) (*pb.TestHTTP3Response, error) {
+		if call == 1 {
+			// Bản gốc treo tới khi bị huỷ vì bản sao đã thắng
+			select {
+			case <-ctx.Done():
+				close(firstCanceled)
+				return nil, status.FromContextError(ctx.Err()).Err()
+			case <-time.After(5 * time.Second):
+				return nil, errors.New("first call was not canceled")
+			}
+		}
+		return &pb.TestHTTP3Response{Status: "hedged"}, nil
+	}
+	c, g := newTestClient(t, slowFirst, WithHedging(HedgePolicy{Delay: 30 * time.Millisecond, MaxHedges: 1}))
+
+	start := time.Now()
+	resp, err := c.Query(context.Background(), &pb.TestHTTP3Request{}, Idempotent())
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	elapsed := time.Since(start)
+	if resp.GetStatus() != "hedged" || g.calls.Load() != 2 {
+		t.Fatalf("status=%q calls=%d, want the hedge's answer after 2 calls", resp.GetStatus(), g.calls.Load())
+	}
+	if elapsed < 30*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("Query took %v, want the hedge sent after the 30ms delay", elapsed)
+	}
+	select {
+	case <-firstCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("original call was not canceled after the hedge won")
+	}
+}
+
+func TestHedgingOnlyForIdempotent(t *testing.T) {
+	c, g := newTestClient(t, func(ctx context.Context, _ int) (*pb.TestHTTP3Response, error) {
+		time.Sleep(60 * time.Millisecond)
+		return &pb.TestHTTP3Response{Status: "ok"}, nil
+	}, WithHedging(HedgePolicy{Delay: 10 * time.Millisecond, MaxHedges: 2}))
+
+	if _, err := c.Query(context.Background(), &pb.TestHTTP3Request{}); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if n := g.calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1: non-idempotent queries must not be hedged", n)
+	}
+}
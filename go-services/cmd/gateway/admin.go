@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+
+	wk "github/shieldx-bot/laminar/internal/worker"
+	pb "github/shieldx-bot/laminar/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// adminTokenKey là metadata key mang token cho các RPC quản trị.
+const adminTokenKey = "x-admin-token"
+
+// ResizeShards đổi số worker shard lúc runtime.
+func (s *server) ResizeShards(ctx context.Context, req *pb.ResizeShardsRequest) (*pb.ResizeShardsResponse, error) {
+	if err := s.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	prev, err := s.cs.Resize(int(req.GetShards()))
+	if err != nil {
+		return nil, err
+	}
+	s.log.InfoContext(ctx, "resized worker shards", slog.Int("previous", prev), slog.Int("shards", int(req.GetShards())))
+	return &pb.ResizeShardsResponse{PreviousShards: int32(prev), Shards: req.GetShards()}, nil
+}
+
+// UpdateTuning đổi các tham số worker được đặt trong req lúc runtime.
+func (s *server) UpdateTuning(ctx context.Context, req *pb.UpdateTuningRequest) (*pb.UpdateTuningResponse, error) {
+	if err := s.checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	prev, cur, err := s.cs.UpdateTuning(func(t *wk.Tuning) {
+		if req.HighWaterMark != nil {
+			t.HighWaterMark = int(req.GetHighWaterMark())
+		}
+		if req.LowWaterMark != nil {
+			t.LowWaterMark = int(req.GetLowWaterMark())
+		}
+		if req.MaxInboxDepth != nil {
+			t.MaxInboxDepth = int(req.GetMaxInboxDepth())
+		}
+		if req.StealThreshold != nil {
+			t.StealThreshold = int(req.GetStealThreshold())
+		}
+		if req.MaxQueueAge != nil {
+			t.MaxQueueAge = req.GetMaxQueueAge().AsDuration()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateTuningResponse{Previous: tuningProto(prev), Current: tuningProto(cur)}, nil
+}
+
+func tuningProto(t wk.Tuning) *pb.Tuning {
+	return &pb.Tuning{
+		HighWaterMark:  int32(t.HighWaterMark),
+		LowWaterMark:   int32(t.LowWaterMark),
+		MaxInboxDepth:  int32(t.MaxInboxDepth),
+		StealThreshold: int32(t.StealThreshold),
+		MaxQueueAge:    durationpb.New(t.MaxQueueAge),
+	}
+}
+
+// checkAdmin chỉ cho qua khi metadata có đúng LAMINAR_ADMIN_TOKEN.
+// Không cấu hình token thì mọi RPC quản trị bị tắt.
+func (s *server) checkAdmin(ctx context.Context) error {
+	if s.adminToken == "" {
+		return status.Error(codes.PermissionDenied, "admin RPCs are disabled")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, t := range md.Get(adminTokenKey) {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(s.adminToken)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid admin token")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	wk "github/shieldx-bot/laminar/internal/worker"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+const testAdminToken = "secret"
+
+// newTestServer trả về server gRPC với ComputeServer không có DB (chỉ chạy
+// được WorkRequest và RPC quản trị).
+func newTestServer(t *testing.T, opts ...wk.Option) *server {
+	t.Helper()
+	log := slog.New(slog.DiscardHandler)
+	cs, err := wk.NewComputeServer(nil, append([]wk.Option{wk.WithShards(2), wk.WithLogger(log)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		cs.Shutdown(ctx)
+	})
+	return &server{cs: cs, adminToken: testAdminToken, log: log}
+}
+
+func adminContext() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(adminTokenKey, testAdminToken))
+}
+
+func TestUpdateTuning(t *testing.T) {
+	initial := wk.Tuning{HighWaterMark: 80, LowWaterMark: 40, MaxInboxDepth: 60, StealThreshold: 16, MaxQueueAge: 3 * time.Second}
+	tests := []struct {
+		name     string
+		req      *pb.UpdateTuningRequest
+		want     wk.Tuning
+		wantCode codes.Code
+	}{
+		{"empty request returns current values", &pb.UpdateTuningRequest{}, initial, codes.OK},
+		{"partial update changes only set fields", &pb.UpdateTuningRequest{
+			HighWaterMark: proto.Int32(100),
+			MaxQueueAge:   durationpb.New(time.Second),
+		}, wk.Tuning{HighWaterMark: 100, LowWaterMark: 40, MaxInboxDepth: 60, StealThreshold: 16, MaxQueueAge: time.Second}, codes.OK},
+		{"zero is a value, not unset", &pb.UpdateTuningRequest{
+			StealThreshold: proto.Int32(0),
+		}, wk.Tuning{HighWaterMark: 80, LowWaterMark: 40, MaxInboxDepth: 60, StealThreshold: 0, MaxQueueAge: 3 * time.Second}, codes.OK},
+		{"low above high is rejected", &pb.UpdateTuningRequest{LowWaterMark: proto.Int32(90)}, initial, codes.InvalidArgument},
+		{"low equal to high is rejected", &pb.UpdateTuningRequest{
+			HighWaterMark: proto.Int32(20), LowWaterMark: proto.Int32(20),
+		}, initial, codes.InvalidArgument},
+		{"non-positive inbox depth is rejected", &pb.UpdateTuningRequest{MaxInboxDepth: proto.Int32(0)}, initial, codes.InvalidArgument},
+		{"negative queue age is rejected", &pb.UpdateTuningRequest{MaxQueueAge: durationpb.New(-time.Second)}, initial, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, wk.WithTuning(initial))
+			resp, err := s.UpdateTuning(adminContext(), tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("UpdateTuning error = %v, want code %v", err, tt.wantCode)
+			}
+			// Bị từ chối thì tuning đang chạy giữ nguyên
+			if got := s.cs.Tuning(); got != tt.want {
+				t.Fatalf("tuning = %+v, want %+v", got, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if !proto.Equal(resp.GetPrevious(), tuningProto(initial)) {
+				t.Errorf("previous = %v, want %v", resp.GetPrevious(), tuningProto(initial))
+			}
+			if !proto.Equal(resp.GetCurrent(), tuningProto(tt.want)) {
+				t.Errorf("current = %v, want %v", resp.GetCurrent(), tuningProto(tt.want))
+			}
+		})
+	}
+}
+
+func TestAdminRPCsRequireToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string // token cấu hình trên server
+		ctx   context.Context
+		want  codes.Code
+	}{
+		{"disabled without configured token", "", adminContext(), codes.PermissionDenied},
+		{"missing token", testAdminToken, context.Background(), codes.Unauthenticated},
+		{"wrong token", testAdminToken, metadata.NewIncomingContext(context.Background(), metadata.Pairs(adminTokenKey, "guess")), codes.Unauthenticated},
+		{"valid token", testAdminToken, adminContext(), codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			s.adminToken = tt.token
+			if _, err := s.UpdateTuning(tt.ctx, &pb.UpdateTuningRequest{}); status.Code(err) != tt.want {
+				t.Fatalf("UpdateTuning = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// SubscribeToEvents đẩy các event của ComputeServer (kết quả worker, đổi chế độ
+// hàng đợi, từ chối vì quá tải) tới client. Topic "*" nhận tất cả.
+// Client đọc chậm sẽ bị drop event theo policy của hub thay vì làm nghẽn worker.
+func (s *server) SubscribeToEvents(req *pb.EventSubscription, stream pb.LaminarGateway_SubscribeToEventsServer) error {
+	if req.GetTopic() == "" {
+		return status.Error(codes.InvalidArgument, "topic is required")
+	}
+	if s.hub == nil {
+		return status.Error(codes.Unavailable, "event hub is not enabled")
+	}
+
+	sub := s.hub.Subscribe(req.GetTopic())
+	defer func() {
+		sub.Close()
+		if n := sub.Dropped(); n > 0 {
+			s.log.WarnContext(stream.Context(), "event subscriber dropped events", slog.String("topic", req.GetTopic()), slog.Uint64("dropped", n))
+		}
+	}()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
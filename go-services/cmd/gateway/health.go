@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// healthServices là các service báo trạng thái qua grpc.health.v1; "" là
+// trạng thái chung của cả server. Mọi RPC của LaminarGateway cần Postgres.
+var healthServices = []string{"", pb.LaminarGateway_ServiceDesc.ServiceName}
+
+// watchDB ping Postgres mỗi interval và đặt trạng thái health của các service
+// theo kết quả cho tới khi ctx xong. Khi drain, hs.Shutdown() đặt NOT_SERVING
+// và bỏ qua mọi cập nhật sau đó.
+func watchDB(ctx context.Context, hs *health.Server, db *sql.DB, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		st := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		if st != last {
+			for _, svc := range healthServices {
+				hs.SetServingStatus(svc, st)
+			}
+			if err != nil {
+				slog.Warn("DB ping failed, gRPC health set to NOT_SERVING", slog.Any("error", err))
+			} else if last != healthpb.HealthCheckResponse_UNKNOWN {
+				slog.Info("DB reachable again, gRPC health set to SERVING")
+			}
+			last = st
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -3,63 +3,124 @@ package main
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github/shieldx-bot/laminar/config"
+	"github/shieldx-bot/laminar/internal/admin"
+	"github/shieldx-bot/laminar/internal/events"
 	wk "github/shieldx-bot/laminar/internal/worker"
+	"github/shieldx-bot/laminar/logging"
 	pb "github/shieldx-bot/laminar/pb"
+	"github/shieldx-bot/laminar/tracing"
 
 	_ "github.com/lib/pq" // Driver postgres
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 type server struct {
 	pb.UnimplementedLaminarGatewayServer
 	db *sql.DB // 1. Thêm field này để tái sử dụng DB Pool
 	cs *wk.ComputeServer
+	// Hub pub/sub cho SubscribeToEvents
+	hub *events.Hub
+
+	// Số request tối đa đang xử lý trên mỗi PipelineProcess stream
+	pipelineWindow int
+
+	// Token cho các RPC quản trị (ResizeShards); rỗng = tắt
+	adminToken string
+
+	log *slog.Logger
+	// Lấy mẫu log TestHTTP3 thành công (nil = ghi hết)
+	successLog *logging.Sampler
 }
 
 // Hàm khởi tạo Server mới, nhận DB từ bên ngoài vào
-func NewServer(db *sql.DB, cs *wk.ComputeServer) *server {
+func NewServer(db *sql.DB, cs *wk.ComputeServer, hub *events.Hub) *server {
 	return &server{
-		db: db,
-		cs: cs,
+		db:             db,
+		cs:             cs,
+		hub:            hub,
+		pipelineWindow: defaultPipelineWindow,
+		log:            slog.Default(),
 	}
 }
 
 func (s *server) PingPong(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
-	fmt.Println("Received Ping:", req.Message)
+	s.log.DebugContext(ctx, "received ping", slog.String("message", req.Message))
 	return &pb.PingResponse{Message: "Pong"}, nil
 }
 
+// ProcessSingle chạy một WorkRequest không cần DB qua worker shard,
+// dùng làm baseline đo overhead của hàng đợi.
+func (s *server) ProcessSingle(ctx context.Context, req *pb.WorkRequest) (*pb.WorkResponse, error) {
+	return s.cs.ExecuteWork(ctx, req)
+}
+
 func (s *server) TestHTTP3(ctx context.Context, req *pb.TestHTTP3Request) (*pb.TestHTTP3Response, error) {
 	// 4. Ở đây bạn có thể dùng s.db để query DB thoải mái
 	// Ví dụ: s.db.QueryContext(ctx, "SELECT 1")
 
 	// Placeholder implementation
 	req = &pb.TestHTTP3Request{
-		QueryId:  req.QueryId,
-		QuerySQL: req.QuerySQL,
-		Payload:  req.Payload,
+		QueryId:       req.QueryId,
+		QuerySQL:      req.QuerySQL,
+		Payload:       req.Payload,
+		QueryName:     req.QueryName,
+		QueryTemplate: req.QueryTemplate,
+		Args:          req.Args,
 	}
 
-	res, err := s.cs.ExecuteQuery(context.Background(), req)
+	// Dùng ctx của RPC (có deadline của client) để query bị huỷ khi client bỏ đi
+	res, err := s.cs.ExecuteQuery(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("Request Test HTTP3 Đã Nhận Và Sử Lý Thành Công !\n")
-	return &pb.TestHTTP3Response{
-		Status:       res.Status,
-		QueryId:      res.QueryId,
-		Records:      res.Records,
-		ReceivedSize: res.ReceivedSize,
-	}, nil
-
+	logging.Success(ctx, s.log, s.successLog, "TestHTTP3 served",
+		slog.String(logging.KeyQueryID, res.QueryId), slog.Int("records", len(res.Records)))
+	return res, nil
 }
 
 func main() {
+	// 1. ĐỌC CẤU HÌNH: mặc định < file (-config) < LAMINAR_* < flag
+	cfg, err := config.Load("gateway", os.Args[1:])
+	if err != nil {
+		slog.Error("invalid config", slog.Any("error", err))
+		os.Exit(2)
+	}
+
+	// Logger slog dùng chung (JSON mặc định); level đổi lại được bằng SIGHUP
+	logger, err := logging.New(cfg.Log, "laminar-gateway")
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
+
+	// Tracing: trace context W3C từ metadata gRPC, export span qua OTLP
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "laminar-gateway")
+	if err != nil {
+		panic(err)
+	}
+
 	// 2. KHỞI TẠO KẾT NỐI DB MỘT LẦN DUY NHẤT LÚC STARTUP
-	connStr := "host=34.177.108.132 port=5432 user=postgres password=Vananh12345@ dbname=laminar sslmode=disable"
+	connStr, err := cfg.DB.DSN()
+	if err != nil {
+		panic(err)
+	}
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		panic(err)
@@ -68,36 +129,151 @@ func main() {
 	defer db.Close()
 
 	// Cấu hình Connection Pool (Quan trọng cho High Performance)
-	db.SetMaxOpenConns(200)  // Giới hạn max 1000 kết nối cùng lúc
-	db.SetMaxIdleConns(25)   // Giữ 25 kết nối rảnh để dùng ngay
-	db.SetConnMaxLifetime(0) // 0 = dùng mãi mãi (hoặc set time để refresh)
+	cfg.DB.ApplyPool(db)
 
 	// Ping kiểm tra
 	if err := db.Ping(); err != nil {
-		fmt.Println("DB Fail:", err)
+		logger.Error("DB ping failed", slog.Any("error", err))
 		// Có thể return hoặc panic tùy chiến lược
 	} else {
-		fmt.Println("Connected to DB successfully")
+		logger.Info("connected to DB")
 	}
 
 	// 2.5 KHỞI TẠO COMPUTE SERVER (WORKER POOL) MỘT LẦN
-	computeServer := wk.NewComputeServer(db)
+	hub := events.NewHub(cfg.GRPC.EventBuffer, events.DropOldest)
+	opts, err := wk.ConfigOptions(cfg)
+	if err != nil {
+		panic(err)
+	}
+	// Metric Prometheus của worker, DB pool và Go runtime
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	opts = append(opts, wk.WithEventHub(hub), wk.WithMetrics(reg), wk.WithLogger(logger))
+	computeServer, err := wk.NewComputeServer(db, opts...)
+	if err != nil {
+		logger.Error("failed to create compute server", slog.Any("error", err))
+		return
+	}
 
 	// Start mảng mạng
-	list, err := net.Listen("tcp", ":50051")
+	list, err := net.Listen("tcp", cfg.GRPC.Listen)
 	if err != nil {
-		fmt.Println("Failed to listen:", err)
+		logger.Error("failed to listen", slog.String("addr", cfg.GRPC.Listen), slog.Any("error", err))
 		return
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
 
 	// 3. TRUYỀN DB VÀ COMPUTE SERVER VÀO GATEWAY
-	myServer := NewServer(db, computeServer)
+	myServer := NewServer(db, computeServer, hub)
+	myServer.pipelineWindow = cfg.GRPC.PipelineWindow
+	// RPC quản trị (ResizeShards, UpdateTuning) cần metadata x-admin-token = admin token
+	myServer.adminToken = cfg.Admin.Token
+	myServer.log = logger
+	myServer.successLog = logging.NewSampler(cfg.Log.SuccessSample)
 	pb.RegisterLaminarGatewayServer(grpcServer, myServer)
 
-	fmt.Println("gRPC server listening on :50051")
-	if err := grpcServer.Serve(list); err != nil {
-		fmt.Println("Failed to serve:", err)
+	// grpc.health.v1: NOT_SERVING tới khi ping Postgres đầu tiên thành công,
+	// theo kết quả ping định kỳ, và khi bắt đầu drain
+	healthSrv := health.NewServer()
+	for _, svc := range healthServices {
+		healthSrv.SetServingStatus(svc, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	healthpb.RegisterHealthServer(grpcServer, healthSrv)
+	// Server reflection để grpcurl/grpcui liệt kê và gọi được service
+	reflection.Register(grpcServer)
+
+	// 4. TẮT ÊM (GRACEFUL SHUTDOWN) KHI NHẬN SIGTERM/SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go watchDB(ctx, healthSrv, db, time.Duration(cfg.Health.Interval), time.Duration(cfg.Health.Timeout))
+
+	// SIGHUP: đọc lại cấu hình, áp dụng phần tuning của worker và log level
+	go computeServer.ReloadOnSIGHUP(ctx, func() (*config.Config, error) {
+		cfg, err := config.Load("gateway", os.Args[1:])
+		if err == nil {
+			err = logging.SetLevel(cfg.Log.Level)
+		}
+		return cfg, err
+	})
+
+	var metricsSrv *http.Server
+	if cfg.Metrics.Listen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+		metricsSrv = &http.Server{Addr: cfg.Metrics.Listen, Handler: mux}
+		go func() {
+			logger.Info("metrics listening", slog.String("addr", cfg.Metrics.Listen))
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("metrics server failed", slog.Any("error", err))
+			}
+		}()
+	}
+
+	// Admin API (introspection, pprof) trên listener riêng, cần admin token
+	var adminSrv *http.Server
+	if cfg.Admin.Listen != "" {
+		adminSrv = &http.Server{Addr: cfg.Admin.Listen, Handler: admin.NewHandler(computeServer, cfg.Admin.Token)}
+		go func() {
+			logger.Info("admin API listening", slog.String("addr", cfg.Admin.Listen))
+			if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("admin server failed", slog.Any("error", err))
+			}
+		}()
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("gRPC server listening", slog.String("addr", cfg.GRPC.Listen))
+		serveErr <- grpcServer.Serve(list)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			logger.Error("failed to serve", slog.Any("error", err))
+		}
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	}
+	stop()
+	// Báo NOT_SERVING trước khi ngừng nhận RPC để load balancer rút server ra
+	healthSrv.Shutdown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+
+	// Đóng các stream SubscribeToEvents để GracefulStop không phải chờ chúng
+	hub.Close()
+	gracefulStop(shutdownCtx, grpcServer)
+
+	if err := computeServer.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("compute server did not drain in time", slog.Any("error", err))
+	}
+	// Tắt /metrics và admin API sau cùng để vẫn quan sát được lúc hàng đợi xả
+	if metricsSrv != nil {
+		metricsSrv.Shutdown(shutdownCtx)
+	}
+	if adminSrv != nil {
+		adminSrv.Shutdown(shutdownCtx)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Warn("failed to flush traces", slog.Any("error", err))
+	}
+	logger.Info("server stopped")
+}
+
+// gracefulStop chờ các RPC đang chạy kết thúc; hết ctx thì cắt ngang.
+func gracefulStop(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.Stop()
 	}
 }
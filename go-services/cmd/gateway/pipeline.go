@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	wk "github/shieldx-bot/laminar/internal/worker"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// defaultPipelineWindow giới hạn số request đang xử lý trên một stream.
+// Nhỏ hơn hẳn buffer 100 slot của mỗi shard để một client tham lam
+// không chiếm hết hàng đợi của mọi worker.
+const defaultPipelineWindow = 32
+
+// PipelineProcess nhận WorkRequest liên tục trên một stream, đẩy từng request
+// vào ComputeServer và trả WorkResponse ngay khi xong (không theo thứ tự gửi,
+// client ghép lại bằng request_id).
+//
+// Flow control: một slot trong window chỉ được trả lại sau khi response đã
+// Send xong, nên client đọc chậm cũng tự động làm server ngừng Recv.
+func (s *server) PipelineProcess(stream pb.LaminarGateway_PipelineProcessServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	window := s.pipelineWindow
+	if window <= 0 {
+		window = defaultPipelineWindow
+	}
+	slots := make(chan struct{}, window)
+	out := make(chan *pb.WorkResponse, window)
+
+	// stream.Send không an toàn khi gọi đồng thời -> một goroutine gửi duy nhất.
+	sendDone := make(chan error, 1)
+	go func() {
+		var sendErr error
+		for resp := range out {
+			if sendErr == nil {
+				if err := stream.Send(resp); err != nil {
+					sendErr = err
+					cancel()
+				}
+			}
+			<-slots
+		}
+		sendDone <- sendErr
+	}()
+
+	var wg sync.WaitGroup
+	recvErr := func() error {
+		for {
+			req, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			wg.Add(1)
+			go func(req *pb.WorkRequest) {
+				defer wg.Done()
+				resp, err := s.cs.ExecuteWork(ctx, req)
+				if err != nil {
+					resp = &pb.WorkResponse{
+						RequestId:    req.GetRequestId(),
+						Success:      false,
+						ErrorMessage: err.Error(),
+						ErrorReason:  wk.ErrorReason(err),
+					}
+				}
+				out <- resp
+			}(req)
+		}
+	}()
+
+	wg.Wait()
+	close(out)
+	sendErr := <-sendDone
+
+	if recvErr != nil {
+		return recvErr
+	}
+	return sendErr
+}
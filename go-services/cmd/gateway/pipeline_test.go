@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	wk "github/shieldx-bot/laminar/internal/worker"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// dial chạy srv trên bufconn và trả về client gRPC tới nó.
+func dial(t *testing.T, srv *server) pb.LaminarGatewayClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	pb.RegisterLaminarGatewayServer(g, srv)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewLaminarGatewayClient(conn)
+}
+
+func TestPipelineReportsPerRequestErrors(t *testing.T) {
+	client := dial(t, newTestServer(t))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.PipelineProcess(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Request lỗi nằm giữa các request hợp lệ: stream vẫn tiếp tục
+	reqs := []*pb.WorkRequest{
+		{RequestId: "ok-1"},
+		{RequestId: "bad", SimulatedWorkLoadMs: -1},
+		{RequestId: "ok-2", SimulatedWorkLoadMs: 1},
+	}
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("Send(%s): %v", req.RequestId, err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]*pb.WorkResponse{}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream closed with %v after %d responses, want EOF", err, len(got))
+		}
+		got[resp.GetRequestId()] = resp
+	}
+	if len(got) != len(reqs) {
+		t.Fatalf("got %d responses, want %d", len(got), len(reqs))
+	}
+	for _, id := range []string{"ok-1", "ok-2"} {
+		if r := got[id]; !r.GetSuccess() || r.GetErrorMessage() != "" {
+			t.Errorf("%s = %v, want success", id, r)
+		}
+	}
+	bad := got["bad"]
+	if bad.GetSuccess() || bad.GetErrorMessage() == "" || bad.GetErrorReason() != wk.ReasonInvalidQuery {
+		t.Errorf("bad = %v, want success=false with error_message and reason %s", bad, wk.ReasonInvalidQuery)
+	}
+}
+
+func TestPipelineAnswersEveryRequestPastTheWindow(t *testing.T) {
+	srv := newTestServer(t)
+	srv.pipelineWindow = 2
+	client := dial(t, srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.PipelineProcess(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 10
+	go func() {
+		for i := 0; i < n; i++ {
+			stream.Send(&pb.WorkRequest{RequestId: fmt.Sprintf("r%d", i), SimulatedWorkLoadMs: 1})
+		}
+		stream.CloseSend()
+	}()
+	for i := 0; i < n; i++ {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv %d: %v", i, err)
+		}
+		if !resp.GetSuccess() {
+			t.Fatalf("%s failed: %s", resp.GetRequestId(), resp.GetErrorMessage())
+		}
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("Recv after all responses = %v, want EOF", err)
+	}
+}
@@ -2,34 +2,27 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
-	"time"
 
-	"github/shieldx-bot/laminar/pb"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github/shieldx-bot/laminar/client"
 )
 
 func main() {
-	addr := "localhost:50051"
+	addr := flag.String("addr", "localhost:50051", "gateway gRPC address")
+	flag.Parse()
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	c, err := client.New(*addr)
 	if err != nil {
-		log.Fatalf("dial %s: %v", addr, err)
+		log.Fatalf("dial %s: %v", *addr, err)
 	}
-	defer conn.Close()
-
-	client := pb.NewLaminarGatewayClient(conn)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	defer c.Close()
 
-	resp, err := client.PingPong(ctx, &pb.PingRequest{Message: "Hello, Laminar!"})
+	msg, err := c.Ping(context.Background(), "Hello, Laminar!")
 	if err != nil {
 		log.Fatalf("PingPong: %v", err)
 	}
 
-	fmt.Printf("PingPong response: %s\n", resp.GetMessage())
+	fmt.Printf("PingPong response: %s\n", msg)
 }
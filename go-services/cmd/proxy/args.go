@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// parseArgs chuyển mảng JSON "Args" thành QueryArg có kiểu.
+// Số nguyên -> int_value, số thực -> double_value, null -> null_value.
+// Object/array không được hỗ trợ.
+func parseArgs(raw []json.RawMessage) ([]*pb.QueryArg, error) {
+	args := make([]*pb.QueryArg, 0, len(raw))
+	for i, r := range raw {
+		dec := json.NewDecoder(bytes.NewReader(r))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("arg $%d: %w", i+1, err)
+		}
+
+		switch x := v.(type) {
+		case nil:
+			args = append(args, &pb.QueryArg{Value: &pb.QueryArg_NullValue{NullValue: true}})
+		case string:
+			args = append(args, &pb.QueryArg{Value: &pb.QueryArg_StringValue{StringValue: x}})
+		case bool:
+			args = append(args, &pb.QueryArg{Value: &pb.QueryArg_BoolValue{BoolValue: x}})
+		case json.Number:
+			if n, err := x.Int64(); err == nil {
+				args = append(args, &pb.QueryArg{Value: &pb.QueryArg_IntValue{IntValue: n}})
+				break
+			}
+			f, err := x.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("arg $%d: %w", i+1, err)
+			}
+			args = append(args, &pb.QueryArg{Value: &pb.QueryArg_DoubleValue{DoubleValue: f}})
+		default:
+			return nil, fmt.Errorf("arg $%d: unsupported JSON type %T", i+1, v)
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"math"
+	"strconv"
+
+	wk "github/shieldx-bot/laminar/internal/worker"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/status"
+)
+
+// writeError trả lỗi của ComputeServer với HTTP status tương ứng mã gRPC
+// (429/503/504/400/403...), kèm Retry-After khi lỗi có RetryInfo.
+func writeError(c *gin.Context, err error) {
+	if d, ok := wk.RetryAfter(err); ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+	}
+	st := status.Convert(err)
+	body := gin.H{"error": st.Message(), "code": st.Code().String()}
+	if reason := wk.ErrorReason(err); reason != "" {
+		body["reason"] = reason
+	}
+	c.JSON(wk.HTTPStatus(err), body)
+}
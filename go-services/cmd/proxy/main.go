@@ -2,18 +2,32 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"database/sql"
 	pb "github/shieldx-bot/laminar/pb"
 
+	"github/shieldx-bot/laminar/config"
+	"github/shieldx-bot/laminar/healthcheck"
+	"github/shieldx-bot/laminar/internal/admin"
 	wk "github/shieldx-bot/laminar/internal/worker"
+	"github/shieldx-bot/laminar/logging"
+	"github/shieldx-bot/laminar/tracing"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq" // Driver postgres
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type server struct {
@@ -30,7 +44,30 @@ func NewServer(db *sql.DB, cs *wk.ComputeServer) *server {
 	}
 }
 func main() {
-	connStr := "host=34.177.108.132 port=5432 user=postgres password=Vananh12345@ dbname=laminar sslmode=disable"
+	// Đọc cấu hình: mặc định < file (-config) < LAMINAR_* < flag
+	cfg, err := config.Load("proxy", os.Args[1:])
+	if err != nil {
+		slog.Error("invalid config", slog.Any("error", err))
+		os.Exit(2)
+	}
+
+	// Logger slog dùng chung (JSON mặc định); level đổi lại được bằng SIGHUP
+	logger, err := logging.New(cfg.Log, "laminar-proxy")
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(logger)
+
+	// Tracing: trace context W3C từ header HTTP, export span qua OTLP
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "laminar-proxy")
+	if err != nil {
+		panic(err)
+	}
+
+	connStr, err := cfg.DB.DSN()
+	if err != nil {
+		panic(err)
+	}
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		panic(err)
@@ -39,25 +76,38 @@ func main() {
 	defer db.Close()
 
 	// Cấu hình Connection Pool (Quan trọng cho High Performance)
-	db.SetMaxOpenConns(200)  // Giới hạn max 1000 kết nối cùng lúc
-	db.SetMaxIdleConns(25)   // Giữ 25 kết nối rảnh để dùng ngay
-	db.SetConnMaxLifetime(0) // 0 = dùng mãi mãi (hoặc set time để refresh)
+	cfg.DB.ApplyPool(db)
 
 	// Ping kiểm tra
 	if err := db.Ping(); err != nil {
-		fmt.Println("DB Fail:", err)
+		logger.Error("DB ping failed", slog.Any("error", err))
 		// Có thể return hoặc panic tùy chiến lược
 	} else {
-		fmt.Println("Connected to DB successfully")
+		logger.Info("connected to DB")
 	}
 
 	// 2.5 KHỞI TẠO COMPUTE SERVER (WORKER POOL) MỘT LẦN
-	computeServer := wk.NewComputeServer(db)
+	opts, err := wk.ConfigOptions(cfg)
+	if err != nil {
+		panic(err)
+	}
+	// Metric Prometheus của worker, DB pool và Go runtime, phục vụ ở /metrics
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	opts = append(opts, wk.WithMetrics(reg), wk.WithLogger(logger))
+	computeServer, err := wk.NewComputeServer(db, opts...)
+	if err != nil {
+		logger.Error("failed to create compute server", slog.Any("error", err))
+		return
+	}
 
 	// HTTP proxy/gateway for benchmarking (can be placed behind Nginx HTTP/3)
 	myServer := NewServer(db, computeServer)
 
-	router := gin.Default()
+	// Access log qua slog thay cho logger mặc định của gin: 2xx được lấy mẫu,
+	// mỗi dòng mang trace id (tracing.Middleware chạy trước) và query_id
+	router := gin.New()
+	router.Use(gin.Recovery(), tracing.Middleware(), logging.GinLogger(logger, logging.NewSampler(cfg.Log.SuccessSample)))
 
 	router.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -65,6 +115,14 @@ func main() {
 		})
 	})
 
+	// /healthz: process còn sống; /readyz: Postgres trả lời ping và chưa drain
+	readiness := healthcheck.NewReadiness(time.Duration(cfg.Health.Timeout))
+	readiness.Add("db", healthcheck.DB(db))
+	router.GET("/healthz", healthcheck.Liveness())
+	router.GET("/readyz", readiness.Handler())
+
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
+
 	// Fast endpoint for QUIC multiplexing tests (small response)
 	router.GET("/fast", func(c *gin.Context) {
 		c.Header("Cache-Control", "no-store")
@@ -74,23 +132,35 @@ func main() {
 	// POST query endpoint (good for load tests; avoids any accidental intermediary caching)
 	router.POST("/TestHTTP3", func(c *gin.Context) {
 		var jsonReq struct {
-			QueryId  string `json:"QueryId"`
-			QuerySQL string `json:"QuerySQL"`
-			Payload  string `json:"Payload"`
+			QueryId       string            `json:"QueryId"`
+			QuerySQL      string            `json:"QuerySQL"`
+			QueryName     string            `json:"QueryName"`
+			QueryTemplate string            `json:"QueryTemplate"`
+			Args          []json.RawMessage `json:"Args"`
+			Payload       string            `json:"Payload"`
 		}
 		if err := c.BindJSON(&jsonReq); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		args, err := parseArgs(jsonReq.Args)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		pbReq := &pb.TestHTTP3Request{
-			QueryId:  jsonReq.QueryId,
-			QuerySQL: jsonReq.QuerySQL,
-			Payload:  []byte(jsonReq.Payload),
+			QueryId:       jsonReq.QueryId,
+			QuerySQL:      jsonReq.QuerySQL,
+			QueryName:     jsonReq.QueryName,
+			QueryTemplate: jsonReq.QueryTemplate,
+			Args:          args,
+			Payload:       []byte(jsonReq.Payload),
 		}
-		res, err := myServer.cs.ExecuteQuery(context.Background(), pbReq)
+		withQueryID(c, pbReq.QueryId)
+		res, err := myServer.cs.ExecuteQuery(c.Request.Context(), pbReq)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
 		c.Header("Cache-Control", "no-store")
@@ -99,6 +169,7 @@ func main() {
 			"query_id":      res.QueryId,
 			"received_size": res.ReceivedSize,
 			"record_count":  len(res.Records),
+			"truncated":     res.Truncated,
 		})
 	})
 
@@ -117,12 +188,15 @@ func main() {
 		}
 
 		pbReq := &pb.TestHTTP3Request{
-			QueryId:  fmt.Sprintf("req_%d", id),
-			QuerySQL: fmt.Sprintf("SELECT id, username, email, password_hash, balance, is_active, created_at, updated_at FROM users WHERE id = %d", id),
+			QueryId: fmt.Sprintf("req_%d", id),
+			// Query đăng ký sẵn trong registry (xem queries.example.yaml)
+			QueryName: "user_by_id",
+			Args:      []*pb.QueryArg{{Value: &pb.QueryArg_IntValue{IntValue: int64(id)}}},
 		}
-		res, err := myServer.cs.ExecuteQuery(context.Background(), pbReq)
+		withQueryID(c, pbReq.QueryId)
+		res, err := myServer.cs.ExecuteQuery(c.Request.Context(), pbReq)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			writeError(c, err)
 			return
 		}
 		c.Header("Cache-Control", "no-store")
@@ -131,6 +205,7 @@ func main() {
 			"query_id":      res.QueryId,
 			"received_size": res.ReceivedSize,
 			"record_count":  len(res.Records),
+			"truncated":     res.Truncated,
 		})
 	})
 
@@ -152,13 +227,77 @@ func main() {
 		}
 
 		// Placeholder: no backend client wired yet; just acknowledge receipt.
-		fmt.Printf("Request Test HTTP3 received (query_id=%s)\n", pbReq.QueryId)
+		// Access log của request đã mang query_id, không cần dòng log riêng.
+		withQueryID(c, pbReq.QueryId)
 		c.JSON(200, gin.H{"status": "ok"})
 	})
-	port := os.Getenv("LAMINAR_PROXY_PORT")
-	if port == "" {
-		port = "8081"
+	srv := &http.Server{
+		Addr:    cfg.HTTP.Listen,
+		Handler: router,
+	}
+
+	// Tắt êm khi nhận SIGTERM/SIGINT: ngừng nhận request, chờ request đang chạy,
+	// rồi xả hàng đợi worker.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// SIGHUP: đọc lại cấu hình, áp dụng phần tuning của worker và log level
+	go computeServer.ReloadOnSIGHUP(ctx, func() (*config.Config, error) {
+		cfg, err := config.Load("proxy", os.Args[1:])
+		if err == nil {
+			err = logging.SetLevel(cfg.Log.Level)
+		}
+		return cfg, err
+	})
+
+	// Admin API (introspection, pprof) trên listener riêng, cần admin token
+	var adminSrv *http.Server
+	if cfg.Admin.Listen != "" {
+		adminSrv = &http.Server{Addr: cfg.Admin.Listen, Handler: admin.NewHandler(computeServer, cfg.Admin.Token)}
+		go func() {
+			logger.Info("admin API listening", slog.String("addr", cfg.Admin.Listen))
+			if err := adminSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("admin server failed", slog.Any("error", err))
+			}
+		}()
 	}
-	fmt.Printf("Starting server on :%s\n", port)
-	router.Run(":" + port) // listen and serve
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("HTTP server listening", slog.String("addr", cfg.HTTP.Listen))
+		serveErr <- srv.ListenAndServe() // listen and serve
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("failed to serve", slog.Any("error", err))
+		}
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	}
+	stop()
+	readiness.SetDraining()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("HTTP server did not stop in time", slog.Any("error", err))
+	}
+	if err := myServer.cs.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("compute server did not drain in time", slog.Any("error", err))
+	}
+	if adminSrv != nil {
+		adminSrv.Shutdown(shutdownCtx)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Warn("failed to flush traces", slog.Any("error", err))
+	}
+	logger.Info("server stopped")
+}
+
+// withQueryID gắn query_id vào context của request để log của worker và
+// access log của request mang theo.
+func withQueryID(c *gin.Context, id string) {
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), slog.String(logging.KeyQueryID, id)))
 }
@@ -0,0 +1,225 @@
+// Package config loads the settings shared by the Laminar binaries: defaults,
+// then a YAML or JSON file, then LAMINAR_* environment variables, then
+// command-line flags, each layer overriding the previous one. Secrets are
+// never part of the file or the source: they are read from the files named by
+// the *_file settings.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Config là toàn bộ cấu hình của gateway gRPC, proxy HTTP và gateway HTTP/3.
+type Config struct {
+	DB       DBConfig       `json:"db" yaml:"db"`
+	GRPC     GRPCConfig     `json:"grpc" yaml:"grpc"`
+	HTTP     HTTPConfig     `json:"http" yaml:"http"`
+	Metrics  MetricsConfig  `json:"metrics" yaml:"metrics"`
+	Tracing  TracingConfig  `json:"tracing" yaml:"tracing"`
+	Log      LogConfig      `json:"log" yaml:"log"`
+	Health   HealthConfig   `json:"health" yaml:"health"`
+	Backend  BackendConfig  `json:"backend" yaml:"backend"`
+	Worker   WorkerConfig   `json:"worker" yaml:"worker"`
+	Registry RegistryConfig `json:"registry" yaml:"registry"`
+	Admin    AdminConfig    `json:"admin" yaml:"admin"`
+
+	// ShutdownTimeout: thời gian tối đa để request đang chạy và hàng đợi xả hết.
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+}
+
+// DBConfig là kết nối và pool Postgres. Mật khẩu chỉ đọc từ PasswordFile;
+// DSNFile (nếu có) chứa nguyên DSN và thay cho các field còn lại.
+type DBConfig struct {
+	Host         string `json:"host" yaml:"host"`
+	Port         int    `json:"port" yaml:"port"`
+	User         string `json:"user" yaml:"user"`
+	Name         string `json:"name" yaml:"name"`
+	SSLMode      string `json:"sslmode" yaml:"sslmode"`
+	PasswordFile string `json:"password_file" yaml:"password_file"`
+	DSNFile      string `json:"dsn_file" yaml:"dsn_file"`
+
+	MaxOpenConns    int      `json:"max_open_conns" yaml:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime" yaml:"conn_max_lifetime"` // 0 = dùng mãi
+}
+
+// GRPCConfig cho cmd/gateway.
+type GRPCConfig struct {
+	Listen string `json:"listen" yaml:"listen"`
+	// PipelineWindow: số request tối đa đang xử lý trên mỗi PipelineProcess stream.
+	PipelineWindow int `json:"pipeline_window" yaml:"pipeline_window"`
+	// EventBuffer: buffer của mỗi subscriber SubscribeToEvents.
+	EventBuffer int `json:"event_buffer" yaml:"event_buffer"`
+}
+
+// HTTPConfig cho cmd/proxy và gateway HTTP/3.
+type HTTPConfig struct {
+	Listen string `json:"listen" yaml:"listen"`
+}
+
+// MetricsConfig: listener riêng phục vụ /metrics của cmd/gateway (binary
+// HTTP phục vụ /metrics trên listener chính). Rỗng = tắt.
+type MetricsConfig struct {
+	Listen string `json:"listen" yaml:"listen"`
+}
+
+// TracingConfig: export span OpenTelemetry qua OTLP/gRPC. Endpoint rỗng thì
+// không export nhưng trace context (W3C) vẫn được truyền tiếp.
+type TracingConfig struct {
+	Endpoint string `json:"endpoint" yaml:"endpoint"` // host:port của collector
+	Insecure bool   `json:"insecure" yaml:"insecure"` // không TLS tới collector
+	// SampleRatio: tỉ lệ trace gốc được lấy mẫu (0..1); trace có parent theo
+	// quyết định của parent.
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio"`
+}
+
+// HealthConfig: chu kỳ ping Postgres cho gRPC health và timeout của mỗi lần
+// kiểm tra (ping DB, Check tới backend gRPC ở /readyz).
+type HealthConfig struct {
+	Interval Duration `json:"interval" yaml:"interval"`
+	Timeout  Duration `json:"timeout" yaml:"timeout"`
+}
+
+// LogConfig cấu hình logger slog. Level đổi được lúc runtime (SIGHUP).
+type LogConfig struct {
+	Level  string `json:"level" yaml:"level"`   // debug, info, warn, error
+	Format string `json:"format" yaml:"format"` // json hoặc text
+	// SuccessSample: chỉ ghi 1 trên SuccessSample log thành công ở đường nóng
+	// (access log 2xx, query thành công); <= 1 = ghi hết.
+	SuccessSample int `json:"success_sample" yaml:"success_sample"`
+}
+
+// BackendConfig là cách gateway HTTP/3 gọi tới gateway gRPC.
+type BackendConfig struct {
+	Addr    string   `json:"addr" yaml:"addr"`
+	Timeout Duration `json:"timeout" yaml:"timeout"`
+	// CacheTTL của cache response ở gateway HTTP/3.
+	CacheTTL Duration `json:"cache_ttl" yaml:"cache_ttl"`
+	// CacheMaxCost: số response tối đa trong cache của gateway HTTP/3.
+	CacheMaxCost int64 `json:"cache_max_cost" yaml:"cache_max_cost"`
+	// PingURL và NaiveURL là service Go HTTP thường dùng để so sánh khi
+	// benchmark (/ping-service-go, /TestHTTP3-service-go). Mặc định rỗng:
+	// endpoint tương ứng trả 503 cho tới khi được cấu hình.
+	PingURL  string `json:"ping_url" yaml:"ping_url"`
+	NaiveURL string `json:"naive_url" yaml:"naive_url"`
+}
+
+// WorkerConfig cấu hình ComputeServer. MaxInboxDepth, MaxQueueAge,
+// StealThreshold và hai watermark được áp dụng lại khi nhận SIGHUP.
+type WorkerConfig struct {
+	Shards         int      `json:"shards" yaml:"shards"` // 0 = runtime.NumCPU()
+	InboxSize      int      `json:"inbox_size" yaml:"inbox_size"`
+	MaxInboxDepth  int      `json:"max_inbox_depth" yaml:"max_inbox_depth"`
+	LoadFactor     float64  `json:"load_factor" yaml:"load_factor"`
+	VirtualNodes   int      `json:"virtual_nodes" yaml:"virtual_nodes"`
+	MaxQueueAge    Duration `json:"max_queue_age" yaml:"max_queue_age"`
+	StealThreshold int      `json:"steal_threshold" yaml:"steal_threshold"` // <= 0 = tắt
+
+	// QueuePolicy: "adaptive-lifo" hoặc "codel".
+	QueuePolicy   string   `json:"queue_policy" yaml:"queue_policy"`
+	HighWaterMark int      `json:"high_water_mark" yaml:"high_water_mark"`
+	LowWaterMark  int      `json:"low_water_mark" yaml:"low_water_mark"`
+	CoDelTarget   Duration `json:"codel_target" yaml:"codel_target"`
+	CoDelInterval Duration `json:"codel_interval" yaml:"codel_interval"`
+
+	// CacheTTL > 0 bật result cache trong worker, chỉ cho query read-only
+	// trong registry.
+	CacheTTL     Duration `json:"cache_ttl" yaml:"cache_ttl"`
+	CacheMaxCost int64    `json:"cache_max_cost" yaml:"cache_max_cost"`
+
+	AdaptiveLimit bool `json:"adaptive_limit" yaml:"adaptive_limit"`
+}
+
+// RegistryConfig: file query registry. Mặc định chỉ chạy query đã đăng ký;
+// AllowAdHoc cho phép cả query_template/QuerySQL tuỳ ý (chỉ dùng khi benchmark).
+type RegistryConfig struct {
+	Path       string `json:"path" yaml:"path"`
+	AllowAdHoc bool   `json:"allow_ad_hoc_sql" yaml:"allow_ad_hoc_sql"`
+}
+
+// AdminConfig: token cho RPC quản trị và admin API HTTP, đọc từ TokenFile.
+// Rỗng = tắt.
+type AdminConfig struct {
+	// Listen là listener riêng của admin API (introspection, pprof); rỗng = tắt.
+	// Nên bind vào địa chỉ nội bộ, ví dụ 127.0.0.1:9465.
+	Listen    string `json:"listen" yaml:"listen"`
+	TokenFile string `json:"token_file" yaml:"token_file"`
+	// Token chỉ set được qua LAMINAR_ADMIN_TOKEN (không đọc từ file cấu hình).
+	Token string `json:"-" yaml:"-"`
+}
+
+// Default trả về cấu hình mặc định, giống các hằng số trước đây trong code.
+func Default() *Config {
+	return &Config{
+		DB: DBConfig{
+			Host:         "localhost",
+			Port:         5432,
+			User:         "postgres",
+			Name:         "laminar",
+			SSLMode:      "disable",
+			MaxOpenConns: 200,
+			MaxIdleConns: 25,
+		},
+		GRPC: GRPCConfig{
+			Listen:         ":50051",
+			PipelineWindow: 32,
+			EventBuffer:    256,
+		},
+		HTTP:    HTTPConfig{Listen: ":8081"},
+		Metrics: MetricsConfig{Listen: ":9464"},
+		Tracing: TracingConfig{SampleRatio: 0.1},
+		Log:     LogConfig{Level: "info", Format: "json", SuccessSample: 100},
+		Health:  HealthConfig{Interval: Duration(5 * time.Second), Timeout: Duration(time.Second)},
+		Backend: BackendConfig{
+			Addr:         "localhost:50051",
+			Timeout:      Duration(3 * time.Second),
+			CacheTTL:     Duration(5 * time.Second),
+			CacheMaxCost: 1 << 30,
+		},
+		Worker: WorkerConfig{
+			InboxSize:      100,
+			MaxInboxDepth:  80,
+			LoadFactor:     1.25,
+			VirtualNodes:   128,
+			MaxQueueAge:    Duration(3 * time.Second),
+			StealThreshold: 16,
+			QueuePolicy:    "adaptive-lifo",
+			HighWaterMark:  80,
+			LowWaterMark:   40,
+			CoDelTarget:    Duration(5 * time.Millisecond),
+			CoDelInterval:  Duration(100 * time.Millisecond),
+		},
+		ShutdownTimeout: Duration(15 * time.Second),
+	}
+}
+
+// Duration đọc được cả "5s" lẫn số nanosecond.
+type Duration time.Duration
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d Duration) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return d.UnmarshalText([]byte(s))
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = Duration(n)
+	return nil
+}
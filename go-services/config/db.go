@@ -0,0 +1,65 @@
+package config
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DSN builds the lib/pq connection string. The password is read from
+// PasswordFile; when DSNFile is set its content is returned as is.
+func (c DBConfig) DSN() (string, error) {
+	if c.DSNFile != "" {
+		dsn, err := readSecret(c.DSNFile)
+		if err != nil {
+			return "", fmt.Errorf("db dsn: %w", err)
+		}
+		return dsn, nil
+	}
+
+	parts := []string{
+		"host=" + quoteDSN(c.Host),
+		fmt.Sprintf("port=%d", c.Port),
+		"user=" + quoteDSN(c.User),
+		"dbname=" + quoteDSN(c.Name),
+	}
+	if c.SSLMode != "" {
+		parts = append(parts, "sslmode="+quoteDSN(c.SSLMode))
+	}
+	if c.PasswordFile != "" {
+		password, err := readSecret(c.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("db password: %w", err)
+		}
+		parts = append(parts, "password="+quoteDSN(password))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// ApplyPool đặt kích thước và thời gian sống của connection pool.
+func (c DBConfig) ApplyPool(db *sql.DB) {
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(c.ConnMaxLifetime))
+}
+
+// quoteDSN đặt giá trị trong nháy đơn theo cú pháp key=value của libpq.
+func quoteDSN(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + r.Replace(v) + "'"
+}
+
+// readSecret đọc secret từ file, bỏ khoảng trắng/xuống dòng ở cuối.
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(string(data), " \t\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return secret, nil
+}
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// setting là một giá trị chỉnh được qua biến môi trường và/hoặc flag.
+type setting struct {
+	env    string // "" = không có biến môi trường
+	flag   string // "" = không có flag
+	usage  string
+	isBool bool
+	set    func(c *Config, v string) error
+}
+
+func str(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func integer(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func float(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func boolean(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func duration(field func(*Config) *Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*field(c) = Duration(d)
+		return nil
+	}
+}
+
+// settings liệt kê mọi biến môi trường và flag được hỗ trợ. Tên biến cũ
+// (LAMINAR_PROXY_PORT, LAMINAR_GRPC_ADDR, ...) giữ nguyên để tương thích.
+var settings = []setting{
+	{env: "LAMINAR_DB_HOST", flag: "db-host", usage: "Postgres host", set: str(func(c *Config) *string { return &c.DB.Host })},
+	{env: "LAMINAR_DB_PORT", flag: "db-port", usage: "Postgres port", set: integer(func(c *Config) *int { return &c.DB.Port })},
+	{env: "LAMINAR_DB_USER", flag: "db-user", usage: "Postgres user", set: str(func(c *Config) *string { return &c.DB.User })},
+	{env: "LAMINAR_DB_NAME", flag: "db-name", usage: "Postgres database", set: str(func(c *Config) *string { return &c.DB.Name })},
+	{env: "LAMINAR_DB_SSLMODE", flag: "db-sslmode", usage: "Postgres sslmode", set: str(func(c *Config) *string { return &c.DB.SSLMode })},
+	{env: "LAMINAR_DB_PASSWORD_FILE", flag: "db-password-file", usage: "file containing the Postgres password", set: str(func(c *Config) *string { return &c.DB.PasswordFile })},
+	{env: "LAMINAR_DB_DSN_FILE", flag: "db-dsn-file", usage: "file containing a full Postgres DSN", set: str(func(c *Config) *string { return &c.DB.DSNFile })},
+	{env: "LAMINAR_DB_MAX_OPEN_CONNS", flag: "db-max-open-conns", usage: "DB pool size", set: integer(func(c *Config) *int { return &c.DB.MaxOpenConns })},
+	{env: "LAMINAR_DB_MAX_IDLE_CONNS", flag: "db-max-idle-conns", usage: "idle connections kept in the DB pool", set: integer(func(c *Config) *int { return &c.DB.MaxIdleConns })},
+
+	{env: "LAMINAR_GRPC_LISTEN", flag: "grpc-listen", usage: "gRPC listen address", set: str(func(c *Config) *string { return &c.GRPC.Listen })},
+	{env: "LAMINAR_HTTP_LISTEN", flag: "http-listen", usage: "HTTP listen address", set: str(func(c *Config) *string { return &c.HTTP.Listen })},
+	{env: "LAMINAR_METRICS_LISTEN", flag: "metrics-listen", usage: "listen address for /metrics of the gRPC gateway (empty disables)", set: str(func(c *Config) *string { return &c.Metrics.Listen })},
+	{env: "LAMINAR_OTLP_ENDPOINT", flag: "otlp-endpoint", usage: "OTLP/gRPC collector for traces (empty disables export)", set: str(func(c *Config) *string { return &c.Tracing.Endpoint })},
+	{env: "LAMINAR_OTLP_INSECURE", flag: "otlp-insecure", usage: "connect to the OTLP collector without TLS", isBool: true, set: boolean(func(c *Config) *bool { return &c.Tracing.Insecure })},
+	{env: "LAMINAR_TRACE_SAMPLE_RATIO", flag: "trace-sample-ratio", usage: "fraction of root traces sampled (0..1)", set: float(func(c *Config) *float64 { return &c.Tracing.SampleRatio })},
+	{env: "LAMINAR_LOG_LEVEL", flag: "log-level", usage: "debug, info, warn or error", set: str(func(c *Config) *string { return &c.Log.Level })},
+	{env: "LAMINAR_LOG_FORMAT", flag: "log-format", usage: "json or text", set: str(func(c *Config) *string { return &c.Log.Format })},
+	{env: "LAMINAR_LOG_SUCCESS_SAMPLE", flag: "log-success-sample", usage: "log one in N hot-path successes (<= 1 logs all)", set: integer(func(c *Config) *int { return &c.Log.SuccessSample })},
+	{env: "LAMINAR_HEALTH_INTERVAL", flag: "health-interval", usage: "how often the gRPC health status pings Postgres", set: duration(func(c *Config) *Duration { return &c.Health.Interval })},
+	{env: "LAMINAR_HEALTH_TIMEOUT", flag: "health-timeout", usage: "timeout of each health/readiness check", set: duration(func(c *Config) *Duration { return &c.Health.Timeout })},
+	{env: "LAMINAR_PROXY_PORT", usage: "HTTP listen port", set: func(c *Config, v string) error {
+		if _, err := strconv.Atoi(v); err != nil {
+			return err
+		}
+		c.HTTP.Listen = ":" + v
+		return nil
+	}},
+	{env: "LAMINAR_GRPC_ADDR", flag: "backend", usage: "gRPC gateway address used by the HTTP/3 gateway", set: str(func(c *Config) *string { return &c.Backend.Addr })},
+	{env: "LAMINAR_BACKEND_PING_URL", flag: "backend-ping-url", usage: "plain HTTP service proxied by /ping-service-go (unset: 503)", set: str(func(c *Config) *string { return &c.Backend.PingURL })},
+	{env: "LAMINAR_BACKEND_NAIVE_URL", flag: "backend-naive-url", usage: "plain HTTP service proxied by /TestHTTP3-service-go (unset: 503)", set: str(func(c *Config) *string { return &c.Backend.NaiveURL })},
+
+	{env: "LAMINAR_SHARDS", flag: "shards", usage: "worker shards (0 = number of CPUs)", set: integer(func(c *Config) *int { return &c.Worker.Shards })},
+	{env: "LAMINAR_QUEUE_POLICY", flag: "queue-policy", usage: "adaptive-lifo or codel", set: str(func(c *Config) *string { return &c.Worker.QueuePolicy })},
+	{env: "LAMINAR_HIGH_WATER_MARK", flag: "high-water-mark", usage: "queue length that switches a class to LIFO", set: integer(func(c *Config) *int { return &c.Worker.HighWaterMark })},
+	{env: "LAMINAR_LOW_WATER_MARK", flag: "low-water-mark", usage: "queue length that switches a class back to FIFO", set: integer(func(c *Config) *int { return &c.Worker.LowWaterMark })},
+	{env: "LAMINAR_MAX_INBOX_DEPTH", flag: "max-inbox-depth", usage: "inbox length at which a shard stops taking new jobs", set: integer(func(c *Config) *int { return &c.Worker.MaxInboxDepth })},
+	{env: "LAMINAR_STEAL_THRESHOLD", flag: "steal-threshold", usage: "queued jobs before idle workers steal (0 disables)", set: integer(func(c *Config) *int { return &c.Worker.StealThreshold })},
+	{env: "LAMINAR_MAX_QUEUE_AGE", flag: "max-queue-age", usage: "drop jobs queued longer than this (0 disables)", set: duration(func(c *Config) *Duration { return &c.Worker.MaxQueueAge })},
+	{env: "LAMINAR_RESULT_CACHE_TTL", flag: "result-cache-ttl", usage: "worker result cache TTL (0 disables)", set: duration(func(c *Config) *Duration { return &c.Worker.CacheTTL })},
+	{env: "LAMINAR_ADAPTIVE_LIMIT", flag: "adaptive-limit", usage: "enable the adaptive concurrency limiter", isBool: true, set: boolean(func(c *Config) *bool { return &c.Worker.AdaptiveLimit })},
+
+	{env: "LAMINAR_QUERY_REGISTRY", flag: "query-registry", usage: "query registry file (.yaml or .json)", set: str(func(c *Config) *string { return &c.Registry.Path })},
+	{env: "LAMINAR_ALLOW_AD_HOC_SQL", flag: "allow-ad-hoc-sql", usage: "also run query_template/QuerySQL statements that are not in the registry", isBool: true, set: boolean(func(c *Config) *bool { return &c.Registry.AllowAdHoc })},
+
+	{env: "LAMINAR_ADMIN_LISTEN", flag: "admin-listen", usage: "admin/introspection HTTP listen address (empty disables)", set: str(func(c *Config) *string { return &c.Admin.Listen })},
+	{env: "LAMINAR_ADMIN_TOKEN_FILE", flag: "admin-token-file", usage: "file containing the admin token", set: str(func(c *Config) *string { return &c.Admin.TokenFile })},
+	// Token không có flag: tham số dòng lệnh lộ ra trong ps
+	{env: "LAMINAR_ADMIN_TOKEN", set: str(func(c *Config) *string { return &c.Admin.Token })},
+
+	{env: "LAMINAR_SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "graceful shutdown timeout", set: duration(func(c *Config) *Duration { return &c.ShutdownTimeout })},
+}
+
+// Load builds the configuration for the binary name from defaults, the file
+// given by -config or LAMINAR_CONFIG, LAMINAR_* environment variables and the
+// flags in args, in that order of precedence, and validates the result.
+func Load(name string, args []string) (*Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("LAMINAR_CONFIG"), "config file (.yaml, .yml or .json)")
+
+	// Flag được ghi lại rồi áp dụng sau cùng, sau file và biến môi trường
+	var fromFlags []func(*Config) error
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+		set := s.set
+		record := func(v string) error {
+			fromFlags = append(fromFlags, func(c *Config) error { return set(c, v) })
+			return nil
+		}
+		if s.isBool {
+			fs.BoolFunc(s.flag, s.usage, record)
+		} else {
+			fs.Func(s.flag, s.usage, record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		if v := os.Getenv(s.env); v != "" {
+			if err := s.set(cfg, v); err != nil {
+				return nil, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, set := range fromFlags {
+		if err := set(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Admin.Token == "" && cfg.Admin.TokenFile != "" {
+		token, err := readSecret(cfg.Admin.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("admin token: %w", err)
+		}
+		cfg.Admin.Token = token
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		// Strict như YAML: key lạ (gõ sai tên) là lỗi thay vì bị bỏ qua
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err = dec.Decode(c); err == nil && dec.More() {
+			err = errors.New("unexpected data after the top-level object")
+		}
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalWithOptions(data, c, yaml.Strict())
+	default:
+		return fmt.Errorf("config %s: unsupported format (want .yaml, .yml or .json)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if c.DB.DSNFile == "" {
+		check(c.DB.Host != "", "db.host is required")
+		check(c.DB.Port > 0 && c.DB.Port < 65536, "db.port %d out of range", c.DB.Port)
+		check(c.DB.User != "", "db.user is required")
+		check(c.DB.Name != "", "db.name is required")
+	}
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns must not be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns must not be negative")
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns (%d) exceeds db.max_open_conns (%d)", c.DB.MaxIdleConns, c.DB.MaxOpenConns)
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime must not be negative")
+
+	check(c.GRPC.Listen != "", "grpc.listen is required")
+	check(c.GRPC.PipelineWindow > 0, "grpc.pipeline_window must be positive")
+	check(c.GRPC.EventBuffer > 0, "grpc.event_buffer must be positive")
+	check(c.HTTP.Listen != "", "http.listen is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio %v must be between 0 and 1", c.Tracing.SampleRatio)
+	var lvl slog.Level
+	check(lvl.UnmarshalText([]byte(strings.ToUpper(c.Log.Level))) == nil, "log.level %q is not debug, info, warn or error", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log.format %q is not json or text", c.Log.Format)
+	check(c.Health.Interval > 0 && c.Health.Timeout > 0, "health.interval and health.timeout must be positive")
+	check(c.Admin.Listen == "" || c.Admin.Token != "", "admin.listen requires an admin token (LAMINAR_ADMIN_TOKEN or admin.token_file)")
+	check(c.Backend.Addr != "", "backend.addr is required")
+	check(c.Backend.Timeout > 0, "backend.timeout must be positive")
+	check(c.Backend.CacheTTL >= 0, "backend.cache_ttl must not be negative")
+	check(c.Backend.CacheMaxCost > 0, "backend.cache_max_cost must be positive")
+	check(httpURL(c.Backend.PingURL), "backend.ping_url %q is not an http(s) URL", c.Backend.PingURL)
+	check(httpURL(c.Backend.NaiveURL), "backend.naive_url %q is not an http(s) URL", c.Backend.NaiveURL)
+
+	w := c.Worker
+	check(w.Shards >= 0, "worker.shards must not be negative")
+	check(w.InboxSize > 0, "worker.inbox_size must be positive")
+	check(w.MaxInboxDepth > 0, "worker.max_inbox_depth must be positive")
+	check(w.LoadFactor >= 1, "worker.load_factor must be at least 1")
+	check(w.VirtualNodes > 0, "worker.virtual_nodes must be positive")
+	check(w.MaxQueueAge >= 0, "worker.max_queue_age must not be negative")
+	check(w.QueuePolicy == "adaptive-lifo" || w.QueuePolicy == "codel",
+		"worker.queue_policy %q is not adaptive-lifo or codel", w.QueuePolicy)
+	check(w.LowWaterMark >= 0 && w.LowWaterMark < w.HighWaterMark,
+		"worker.low_water_mark (%d) must be below worker.high_water_mark (%d)", w.LowWaterMark, w.HighWaterMark)
+	check(w.CoDelTarget > 0 && w.CoDelInterval > 0, "worker.codel_target and worker.codel_interval must be positive")
+	check(w.CacheTTL >= 0, "worker.cache_ttl must not be negative")
+	check(w.CacheMaxCost >= 0, "worker.cache_max_cost must not be negative")
+
+	check(c.ShutdownTimeout > 0, "shutdown_timeout must be positive")
+	return errors.Join(errs...)
+}
+
+// httpURL: rỗng hoặc URL tuyệt đối http/https.
+func httpURL(s string) bool {
+	if s == "" {
+		return true
+	}
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
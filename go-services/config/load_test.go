@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile ghi content vào file name trong thư mục tạm của test và trả về đường dẫn.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "laminar.yaml", "db:\n  host: file-host\nworker:\n  shards: 3\n")
+	tests := []struct {
+		name string
+		file bool
+		env  bool
+		flag bool
+		want string
+	}{
+		{"default", false, false, false, "localhost"},
+		{"file over default", true, false, false, "file-host"},
+		{"env over file", true, true, false, "env-host"},
+		{"flag over env", true, true, true, "flag-host"},
+		{"flag over default", false, false, true, "flag-host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LAMINAR_CONFIG", "")
+			t.Setenv("LAMINAR_DB_HOST", "")
+			var args []string
+			if tt.file {
+				args = append(args, "-config", file)
+			}
+			if tt.env {
+				t.Setenv("LAMINAR_DB_HOST", "env-host")
+			}
+			if tt.flag {
+				args = append(args, "-db-host", "flag-host")
+			}
+			cfg, err := Load("test", args)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.DB.Host != tt.want {
+				t.Errorf("db.host = %q, want %q", cfg.DB.Host, tt.want)
+			}
+			// Field không bị ghi đè giữ giá trị từ file
+			if wantShards := map[bool]int{true: 3, false: 0}[tt.file]; cfg.Worker.Shards != wantShards {
+				t.Errorf("worker.shards = %d, want %d", cfg.Worker.Shards, wantShards)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	path := writeFile(t, "laminar.json", `{"log": {"level": "debug"}, "shutdown_timeout": "3s"}`)
+	t.Setenv("LAMINAR_CONFIG", path)
+	cfg, err := Load("test", nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Log.Level != "debug" || cfg.ShutdownTimeout != Duration(3*time.Second) {
+		t.Fatalf("log.level=%q shutdown_timeout=%v, want debug 3s", cfg.Log.Level, time.Duration(cfg.ShutdownTimeout))
+	}
+}
+
+func TestLoadFileRejectsUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "laminar.yaml", "db:\n  hots: typo\n"},
+		{"json", "laminar.json", `{"db": {"hots": "typo"}}`},
+		{"json trailing data", "laminar.json", `{"db": {"host": "a"}} {}`},
+		{"unsupported format", "laminar.toml", "db = 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LAMINAR_CONFIG", "")
+			if _, err := Load("test", []string{"-config", writeFile(t, tt.file, tt.content)}); err == nil {
+				t.Fatal("Load accepted an invalid config file")
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   []string // mỗi chuỗi phải có trong lỗi; nil = hợp lệ
+	}{
+		{"defaults are valid", func(*Config) {}, nil},
+		{"backend URLs unset", func(c *Config) { c.Backend.PingURL, c.Backend.NaiveURL = "", "" }, nil},
+		{"backend URLs set", func(c *Config) {
+			c.Backend.PingURL, c.Backend.NaiveURL = "http://localhost:8081/api/ping", "https://example.com/naive"
+		}, nil},
+		{"DSN file replaces host", func(c *Config) { c.DB.Host, c.DB.DSNFile = "", "/run/secrets/dsn" }, nil},
+		{"missing host", func(c *Config) { c.DB.Host = "" }, []string{"db.host is required"}},
+		{"bad port", func(c *Config) { c.DB.Port = 70000 }, []string{"db.port 70000 out of range"}},
+		{"relative ping URL", func(c *Config) { c.Backend.PingURL = "localhost:8081/api/ping" }, []string{"backend.ping_url"}},
+		{"non-http naive URL", func(c *Config) { c.Backend.NaiveURL = "ftp://example.com" }, []string{"backend.naive_url"}},
+		{"bad log level", func(c *Config) { c.Log.Level = "verbose" }, []string{`log.level "verbose"`}},
+		{"watermarks inverted", func(c *Config) { c.Worker.LowWaterMark, c.Worker.HighWaterMark = 10, 5 }, []string{"worker.low_water_mark"}},
+		{"admin without token", func(c *Config) { c.Admin.Listen = ":9090" }, []string{"admin.listen requires an admin token"}},
+		{"reports every error", func(c *Config) {
+			c.DB.Host, c.Tracing.SampleRatio, c.ShutdownTimeout = "", 2, 0
+		}, []string{"db.host is required", "tracing.sample_ratio 2", "shutdown_timeout must be positive"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.modify(c)
+			err := c.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate accepted an invalid config, want %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadReportsBadEnv(t *testing.T) {
+	t.Setenv("LAMINAR_CONFIG", "")
+	t.Setenv("LAMINAR_DB_PORT", "not-a-port")
+	_, err := Load("test", nil)
+	if err == nil || !strings.Contains(err.Error(), "LAMINAR_DB_PORT") {
+		t.Fatalf("Load = %v, want an error naming LAMINAR_DB_PORT", err)
+	}
+}
@@ -0,0 +1,33 @@
+// Package errinfo defines the google.rpc.ErrorInfo domain and reasons that
+// the Laminar server attaches to its gRPC errors. The server and the client
+// SDK both import it so a reason can never drift between the two.
+package errinfo
+
+// Domain là domain trong ErrorInfo của mọi lỗi do ComputeServer trả về.
+const Domain = "laminar"
+
+// Reason trong ErrorInfo, ổn định để client dựa vào.
+const (
+	ShardQueueFull   = "SHARD_QUEUE_FULL"
+	ConcurrencyLimit = "CONCURRENCY_LIMIT"
+	ShuttingDown     = "SHUTTING_DOWN"
+	ExpiredInQueue   = "EXPIRED_IN_QUEUE"
+	DroppedByPolicy  = "DROPPED_BY_QUEUE_POLICY"
+	DBUnavailable    = "DB_UNAVAILABLE"
+	InvalidQuery     = "INVALID_QUERY"
+	QueryRejected    = "QUERY_REJECTED"
+	Conflict         = "CONFLICT"
+	Deadline         = "DEADLINE_EXCEEDED"
+	Canceled         = "CANCELED"
+	Internal         = "INTERNAL"
+)
+
+// NotExecuted reports whether reason guarantees the query never reached the
+// database, so even a non-idempotent query can safely be sent again.
+func NotExecuted(reason string) bool {
+	switch reason {
+	case ShardQueueFull, ConcurrencyLimit, ShuttingDown, ExpiredInQueue, DroppedByPolicy:
+		return true
+	}
+	return false
+}
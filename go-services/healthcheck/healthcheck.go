@@ -0,0 +1,129 @@
+// Package healthcheck serves the /healthz (liveness) and /readyz (readiness)
+// endpoints of the Laminar HTTP binaries. Liveness only says the process
+// answers; readiness runs every registered dependency check (Postgres, the
+// upstream gRPC backend) and fails while the process is draining, so load
+// balancers stop sending traffic before shutdown.
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Check trả về nil khi dependency sẵn sàng. ctx mang timeout của readiness.
+type Check func(ctx context.Context) error
+
+// DB checks that Postgres answers a ping.
+func DB(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// GRPC checks the upstream's grpc.health.v1 status of service. An upstream
+// without the health service counts as reachable.
+func GRPC(conn grpc.ClientConnInterface, service string) Check {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if status.Code(err) == codes.Unimplemented {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if s := resp.GetStatus(); s != healthpb.HealthCheckResponse_SERVING {
+			return errors.New(s.String())
+		}
+		return nil
+	}
+}
+
+// Liveness answers 200 while the process can serve HTTP at all.
+func Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Readiness chạy các Check đã đăng ký cho /readyz.
+type Readiness struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewReadiness returns a readiness endpoint whose checks each get timeout.
+func NewReadiness(timeout time.Duration) *Readiness {
+	return &Readiness{timeout: timeout}
+}
+
+// Add registers a dependency check. Call it before serving.
+func (r *Readiness) Add(name string, check Check) {
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// SetDraining makes /readyz fail from now on. Call it when shutdown starts.
+func (r *Readiness) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Handler runs every check concurrently and answers 200 when all pass, 503
+// otherwise, with the result of each check in the body.
+func (r *Readiness) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		if r.draining.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), r.timeout)
+		defer cancel()
+		results := make(map[string]string, len(r.checks))
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		ready := true
+		for _, nc := range r.checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := nc.check(ctx)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					results[nc.name] = err.Error()
+					ready = false
+				} else {
+					results[nc.name] = "ok"
+				}
+			}()
+		}
+		wg.Wait()
+
+		if !ready {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": results})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": results})
+	}
+}
@@ -0,0 +1,117 @@
+// Package admin serves the introspection API of a ComputeServer on its own
+// listener: shard queues and the jobs they are running, cache, limiter,
+// stealing, tuning and DB pool stats as JSON, the log level, and the
+// net/http/pprof profiles. Every endpoint requires the admin token.
+package admin
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	wk "github/shieldx-bot/laminar/internal/worker"
+	"github/shieldx-bot/laminar/logging"
+)
+
+// TokenHeader là header mang admin token, cùng tên với metadata của RPC quản trị.
+// "Authorization: Bearer <token>" cũng được chấp nhận.
+const TokenHeader = "X-Admin-Token"
+
+// Stats là trạng thái tổng hợp của compute server trả về ở /admin/stats.
+type Stats struct {
+	Shards  int             `json:"shards"`
+	Cache   wk.CacheStats   `json:"cache"`
+	Steals  wk.StealStats   `json:"steals"`
+	Limiter wk.LimiterStats `json:"limiter"`
+	Tuning  wk.TuningStats  `json:"tuning"`
+	DB      sql.DBStats     `json:"db"`
+}
+
+// NewHandler returns the admin API of cs:
+//
+//	GET      /admin/shards     per-shard queue depth, modes, oldest job age, in-flight job
+//	GET      /admin/stats      cache, stealing, limiter, tuning and DB pool stats
+//	GET, PUT /admin/log-level  current log level; PUT {"level":"debug"} changes it
+//	GET      /debug/pprof/...  net/http/pprof
+//
+// Durations are in nanoseconds, like the other worker stats. Requests without
+// the token are rejected with 401; an empty token disables the API and every
+// request gets 403.
+func NewHandler(cs *wk.ComputeServer, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/shards", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"time":   time.Now(),
+			"shards": cs.Shards(),
+		})
+	})
+	mux.HandleFunc("GET /admin/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Stats{
+			Shards:  cs.ShardCount(),
+			Cache:   cs.CacheStats(),
+			Steals:  cs.StealStats(),
+			Limiter: cs.LimiterStats(),
+			Tuning:  cs.TuningStats(),
+			DB:      cs.DBStats(),
+		})
+	})
+	mux.HandleFunc("GET /admin/log-level", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"level": logging.Level().String()})
+	})
+	mux.HandleFunc("PUT /admin/log-level", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := logging.SetLevel(req.Level); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		slog.InfoContext(r.Context(), "log level changed via admin API", slog.String("level", logging.Level().String()))
+		writeJSON(w, http.StatusOK, map[string]string{"level": logging.Level().String()})
+	})
+
+	// pprof.Index phục vụ cả các profile theo tên (heap, goroutine, block, ...)
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+
+	return requireToken(token, mux)
+}
+
+// requireToken chỉ cho request có đúng token đi qua (so sánh thời gian hằng).
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "admin API is disabled"})
+			return
+		}
+		got := r.Header.Get(TokenHeader)
+		if got == "" {
+			got, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid admin token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
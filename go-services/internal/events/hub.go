@@ -0,0 +1,207 @@
+// Package events is an in-process topic pub/sub hub. The compute server
+// publishes what its workers are doing (results, queue mode switches,
+// overload rejections) and SubscribeToEvents streams it to dashboards.
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// Topics published by the compute server.
+const (
+	TopicResults   = "worker.results"
+	TopicQueueMode = "queue.mode"
+	TopicOverload  = "overload"
+
+	// TopicAll subscribes to every topic.
+	TopicAll = "*"
+)
+
+// DropPolicy decides what happens when a subscriber's buffer is full.
+// Publishers never block on a slow consumer.
+type DropPolicy int
+
+const (
+	// DropNewest discards the event being published.
+	DropNewest DropPolicy = iota
+	// DropOldest evicts the oldest buffered event to make room, so a slow
+	// consumer always sees the most recent state.
+	DropOldest
+)
+
+const DefaultBufferSize = 256
+
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[string]map[*Subscription]struct{}
+	buffer int
+	policy DropPolicy
+	closed bool
+}
+
+func NewHub(buffer int, policy DropPolicy) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBufferSize
+	}
+	return &Hub{
+		subs:   make(map[string]map[*Subscription]struct{}),
+		buffer: buffer,
+		policy: policy,
+	}
+}
+
+// Subscription is one consumer of a topic. C is closed when the
+// subscription is closed.
+type Subscription struct {
+	C <-chan *pb.WorkResponse
+
+	ch      chan *pb.WorkResponse
+	topic   string
+	hub     *Hub
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+func (h *Hub) Subscribe(topic string) *Subscription {
+	ch := make(chan *pb.WorkResponse, h.buffer)
+	sub := &Subscription{C: ch, ch: ch, topic: topic, hub: h}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		sub.once.Do(func() { close(ch) })
+		return sub
+	}
+	set, ok := h.subs[topic]
+	if !ok {
+		set = make(map[*Subscription]struct{})
+		h.subs[topic] = set
+	}
+	set[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Close unsubscribes and closes C. Safe to call more than once.
+func (sub *Subscription) Close() {
+	sub.once.Do(func() {
+		h := sub.hub
+		h.mu.Lock()
+		if set, ok := h.subs[sub.topic]; ok {
+			delete(set, sub)
+			if len(set) == 0 {
+				delete(h.subs, sub.topic)
+			}
+		}
+		close(sub.ch)
+		h.mu.Unlock()
+	})
+}
+
+// Close ends every subscription (their C channels are closed) and makes later
+// Subscribe calls return an already-closed subscription. Used on shutdown so
+// SubscribeToEvents streams finish instead of blocking a graceful stop.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for topic, set := range h.subs {
+		for sub := range set {
+			sub.once.Do(func() { close(sub.ch) })
+		}
+		delete(h.subs, topic)
+	}
+}
+
+// Dropped returns how many events this subscriber lost to the drop policy.
+func (sub *Subscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// Active reports whether anyone listens to topic, so publishers can skip
+// building expensive payloads. A nil hub is never active.
+func (h *Hub) Active(topic string) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	n := len(h.subs[topic]) + len(h.subs[TopicAll])
+	h.mu.RUnlock()
+	return n > 0
+}
+
+// Publish delivers msg to every subscriber of topic and of TopicAll without
+// blocking. msg.Topic is set to topic. Publishing on a nil hub is a no-op.
+func (h *Hub) Publish(topic string, msg *pb.WorkResponse) {
+	if h == nil {
+		return
+	}
+	msg.Topic = topic
+
+	// Holding the read lock keeps Close from closing a channel mid-send.
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs[topic] {
+		h.deliver(sub, msg)
+	}
+	if topic != TopicAll {
+		for sub := range h.subs[TopicAll] {
+			h.deliver(sub, msg)
+		}
+	}
+}
+
+func (h *Hub) deliver(sub *Subscription, msg *pb.WorkResponse) {
+	select {
+	case sub.ch <- msg:
+		return
+	default:
+	}
+
+	if h.policy == DropNewest {
+		sub.dropped.Add(1)
+		return
+	}
+
+	// DropOldest: evict until the new event fits. Other publishers may race
+	// for the freed slot, so give up after a few attempts.
+	for i := 0; i < 3; i++ {
+		select {
+		case <-sub.ch:
+			sub.dropped.Add(1)
+		default:
+		}
+		select {
+		case sub.ch <- msg:
+			return
+		default:
+		}
+	}
+	sub.dropped.Add(1)
+}
+
+// Event is the JSON document carried in WorkResponse.result_payload for
+// messages published by the compute server.
+type Event struct {
+	Shard    int    `json:"shard"`
+	Priority int32  `json:"priority,omitempty"` // queue.mode: lớp ưu tiên vừa đổi chế độ
+	Kind     string `json:"kind,omitempty"`     // worker.results: "sql" or "work"
+	Mode     string `json:"mode,omitempty"`     // queue.mode: "FIFO" or "LIFO"
+	QueueLen int    `json:"queue_len,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NewMessage wraps ev into the WorkResponse shape streamed to subscribers.
+func NewMessage(requestID string, success bool, ev Event) *pb.WorkResponse {
+	payload, _ := json.Marshal(ev)
+	return &pb.WorkResponse{
+		RequestId:     requestID,
+		Success:       success,
+		ResultPayload: payload,
+	}
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// received trả về request_id của các message đang nằm trong buffer của sub.
+func received(sub *Subscription) []string {
+	var ids []string
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, msg.GetRequestId())
+		default:
+			return ids
+		}
+	}
+}
+
+func TestHubFanOut(t *testing.T) {
+	h := NewHub(16, DropNewest)
+	a := h.Subscribe(TopicResults)
+	b := h.Subscribe(TopicResults)
+	all := h.Subscribe(TopicAll)
+	mode := h.Subscribe(TopicQueueMode)
+
+	h.Publish(TopicResults, &pb.WorkResponse{RequestId: "r1"})
+	h.Publish(TopicQueueMode, &pb.WorkResponse{RequestId: "m1"})
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		want string
+	}{
+		{"first results subscriber", a, "[r1]"},
+		{"second results subscriber", b, "[r1]"},
+		{"wildcard sees every topic", all, "[r1 m1]"},
+		{"other topic", mode, "[m1]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(received(tt.sub)); got != tt.want {
+			t.Errorf("%s received %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHubPublishSetsTopic(t *testing.T) {
+	h := NewHub(1, DropNewest)
+	sub := h.Subscribe(TopicAll)
+	h.Publish(TopicOverload, &pb.WorkResponse{})
+	if msg := <-sub.C; msg.GetTopic() != TopicOverload {
+		t.Fatalf("topic = %q, want %q", msg.GetTopic(), TopicOverload)
+	}
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	h := NewHub(16, DropNewest)
+	a := h.Subscribe(TopicResults)
+	b := h.Subscribe(TopicResults)
+
+	a.Close()
+	a.Close() // gọi lại không panic
+	if _, ok := <-a.C; ok {
+		t.Fatal("C still open after Close")
+	}
+	h.Publish(TopicResults, &pb.WorkResponse{RequestId: "r1"})
+	if got := received(b); len(got) != 1 {
+		t.Fatalf("remaining subscriber received %v, want [r1]", got)
+	}
+	if !h.Active(TopicResults) {
+		t.Fatal("topic inactive while a subscriber remains")
+	}
+
+	b.Close()
+	if h.Active(TopicResults) {
+		t.Fatal("topic still active after every subscriber left")
+	}
+	// Publish không còn ai nghe: không gửi vào channel đã đóng
+	h.Publish(TopicResults, &pb.WorkResponse{RequestId: "r2"})
+}
+
+func TestHubDropPolicy(t *testing.T) {
+	tests := []struct {
+		policy DropPolicy
+		want   string
+	}{
+		{DropNewest, "[1 2]"},
+		{DropOldest, "[3 4]"},
+	}
+	for _, tt := range tests {
+		h := NewHub(2, tt.policy)
+		sub := h.Subscribe(TopicResults)
+		for i := 1; i <= 4; i++ {
+			h.Publish(TopicResults, &pb.WorkResponse{RequestId: fmt.Sprint(i)})
+		}
+		if got := fmt.Sprint(received(sub)); got != tt.want || sub.Dropped() != 2 {
+			t.Errorf("policy %d: received %s with %d dropped, want %s with 2 dropped", tt.policy, got, sub.Dropped(), tt.want)
+		}
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	h := NewHub(4, DropNewest)
+	sub := h.Subscribe(TopicResults)
+	h.Close()
+	if _, ok := <-sub.C; ok {
+		t.Fatal("subscription still open after Hub.Close")
+	}
+	sub.Close() // sau Hub.Close vẫn an toàn
+
+	late := h.Subscribe(TopicResults)
+	if _, ok := <-late.C; ok {
+		t.Fatal("Subscribe after Close returned an open subscription")
+	}
+	var nilHub *Hub
+	nilHub.Publish(TopicResults, &pb.WorkResponse{})
+	if nilHub.Active(TopicResults) {
+		t.Fatal("nil hub is active")
+	}
+}
@@ -0,0 +1,164 @@
+// Package registry holds the named queries the compute server is allowed to
+// run. It is loaded once at startup from a YAML or JSON file.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Query là một câu SQL đã đăng ký.
+type Query struct {
+	Name string `json:"name" yaml:"name"`
+	// SQL dùng placeholder $1, $2, ... cho tham số.
+	SQL string `json:"sql" yaml:"sql"`
+	// ReadOnly: chạy trong transaction READ ONLY, Postgres từ chối mọi lệnh ghi.
+	ReadOnly bool `json:"read_only" yaml:"read_only"`
+	// MaxRows: số dòng tối đa trả về, phần dư bị cắt (0 = không giới hạn).
+	MaxRows int `json:"max_rows" yaml:"max_rows"`
+	// CacheTTL ghi đè TTL mặc định của result cache cho query này. Chỉ query
+	// ReadOnly mới được cache, nên đặt CacheTTL khi read_only: false là lỗi.
+	CacheTTL Duration `json:"cache_ttl" yaml:"cache_ttl"`
+}
+
+// Duration đọc được cả "5s" lẫn số nanosecond.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return d.UnmarshalText([]byte(s))
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = Duration(n)
+	return nil
+}
+
+type file struct {
+	Queries []*Query `json:"queries" yaml:"queries"`
+}
+
+type Registry struct {
+	byName map[string]*Query
+	bySQL  map[string]*Query // key: Normalize(SQL)
+}
+
+// Load đọc registry từ file; định dạng theo phần mở rộng (.yaml/.yml/.json).
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read query registry: %w", err)
+	}
+
+	var f file
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &f)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &f)
+	default:
+		return nil, fmt.Errorf("query registry %s: unsupported format (want .yaml, .yml or .json)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse query registry %s: %w", path, err)
+	}
+	return New(f.Queries...)
+}
+
+// New builds a registry and validates that names and statements are unique.
+func New(queries ...*Query) (*Registry, error) {
+	r := &Registry{
+		byName: make(map[string]*Query, len(queries)),
+		bySQL:  make(map[string]*Query, len(queries)),
+	}
+	for _, q := range queries {
+		if q.Name == "" {
+			return nil, fmt.Errorf("query registry: query without name")
+		}
+		if strings.TrimSpace(q.SQL) == "" {
+			return nil, fmt.Errorf("query registry: %q has empty sql", q.Name)
+		}
+		if q.MaxRows < 0 {
+			return nil, fmt.Errorf("query registry: %q has negative max_rows", q.Name)
+		}
+		if q.CacheTTL < 0 {
+			return nil, fmt.Errorf("query registry: %q has negative cache_ttl", q.Name)
+		}
+		if q.CacheTTL > 0 && !q.ReadOnly {
+			return nil, fmt.Errorf("query registry: %q sets cache_ttl but is not read_only; only read-only queries are cached", q.Name)
+		}
+		if _, dup := r.byName[q.Name]; dup {
+			return nil, fmt.Errorf("query registry: duplicate name %q", q.Name)
+		}
+		r.byName[q.Name] = q
+		r.bySQL[Normalize(q.SQL)] = q
+	}
+	return r, nil
+}
+
+// Lookup tìm query theo tên.
+func (r *Registry) Lookup(name string) (*Query, bool) {
+	q, ok := r.byName[name]
+	return q, ok
+}
+
+// Match tìm query có SQL trùng với sql sau khi chuẩn hoá khoảng trắng.
+func (r *Registry) Match(sql string) (*Query, bool) {
+	q, ok := r.bySQL[Normalize(sql)]
+	return q, ok
+}
+
+// Len trả về số query đã đăng ký.
+func (r *Registry) Len() int {
+	return len(r.byName)
+}
+
+// Normalize gộp khoảng trắng liên tiếp (ngoài chuỗi trong dấu nháy) thành
+// một dấu cách và bỏ dấu ';' ở cuối, để "SELECT  1;" và "SELECT 1" chung key.
+// Không đổi chữ hoa/thường vì literal trong câu query phân biệt hoa thường.
+func Normalize(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	var quote rune
+	pendingSpace := false
+	for _, r := range strings.TrimSpace(query) {
+		if quote != 0 {
+			b.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch r {
+		case ' ', '\t', '\n', '\r':
+			pendingSpace = true
+			continue
+		case '\'', '"':
+			quote = r
+		}
+		if pendingSpace {
+			b.WriteByte(' ')
+			pendingSpace = false
+		}
+		b.WriteRune(r)
+	}
+	return strings.TrimRight(b.String(), "; ")
+}
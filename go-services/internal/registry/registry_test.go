@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile ghi content vào file name trong thư mục tạm của test và trả về đường dẫn.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "queries.yaml", `
+queries:
+  - name: user_by_id
+    sql: SELECT * FROM users WHERE id = $1
+    read_only: true
+    max_rows: 1
+    cache_ttl: 5s
+  - name: touch
+    sql: UPDATE users SET updated_at = now() WHERE id = $1
+`},
+		{"json", "queries.json", `{"queries": [
+  {"name": "user_by_id", "sql": "SELECT * FROM users WHERE id = $1", "read_only": true, "max_rows": 1, "cache_ttl": "5s"},
+  {"name": "touch", "sql": "UPDATE users SET updated_at = now() WHERE id = $1"}
+]}`},
+		{"json nanosecond ttl", "queries.json", `{"queries": [
+  {"name": "user_by_id", "sql": "SELECT * FROM users WHERE id = $1", "read_only": true, "max_rows": 1, "cache_ttl": 5000000000},
+  {"name": "touch", "sql": "UPDATE users SET updated_at = now() WHERE id = $1"}
+]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Load(writeFile(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if r.Len() != 2 {
+				t.Fatalf("Len() = %d, want 2", r.Len())
+			}
+			q, ok := r.Lookup("user_by_id")
+			if !ok {
+				t.Fatal("Lookup(user_by_id) not found")
+			}
+			if !q.ReadOnly || q.MaxRows != 1 || time.Duration(q.CacheTTL) != 5*time.Second {
+				t.Errorf("user_by_id = %+v, want read_only, max_rows 1, cache_ttl 5s", q)
+			}
+			if q, _ := r.Lookup("touch"); q == nil || q.ReadOnly || q.CacheTTL != 0 {
+				t.Errorf("touch = %+v, want a writable query without cache_ttl", q)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"unsupported format", "queries.toml", "", "unsupported format"},
+		{"bad yaml", "queries.yaml", "queries: [", "parse query registry"},
+		{"bad json", "queries.json", `{"queries": `, "parse query registry"},
+		{"bad duration", "queries.yaml", "queries:\n  - name: a\n    sql: SELECT 1\n    read_only: true\n    cache_ttl: soon\n", "parse query registry"},
+		{"duplicate name", "queries.yaml", "queries:\n  - name: a\n    sql: SELECT 1\n  - name: a\n    sql: SELECT 2\n", `duplicate name "a"`},
+		{"missing name", "queries.yaml", "queries:\n  - sql: SELECT 1\n", "query without name"},
+		{"empty sql", "queries.yaml", "queries:\n  - name: a\n    sql: '  '\n", `"a" has empty sql`},
+		{"negative max_rows", "queries.yaml", "queries:\n  - name: a\n    sql: SELECT 1\n    max_rows: -1\n", "negative max_rows"},
+		{"cache_ttl on writable query", "queries.yaml", "queries:\n  - name: a\n    sql: SELECT 1\n    cache_ttl: 1s\n", "not read_only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFile(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("Load of a missing file succeeded")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	q := &Query{Name: "by_name", SQL: "SELECT * FROM users WHERE name = 'A  B' AND id = $1"}
+	r, err := New(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SELECT * FROM users WHERE name = 'A  B' AND id = $1", true},
+		{"  SELECT *\n\tFROM users  WHERE name = 'A  B' AND id = $1 ;", true},
+		{"SELECT * FROM users WHERE name = 'A B' AND id = $1", false},
+		{"select * from users where name = 'A  B' and id = $1", false},
+	}
+	for _, tt := range tests {
+		if got, ok := r.Match(tt.sql); ok != tt.want || ok && got != q {
+			t.Errorf("Match(%q) = %v, %v, want found=%v", tt.sql, got, ok, tt.want)
+		}
+	}
+}
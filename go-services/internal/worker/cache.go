@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"google.golang.org/protobuf/proto"
+
+	"github/shieldx-bot/laminar/internal/registry"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// CacheConfig bật cache kết quả (Cache-Aside) trong từng worker shard.
+type CacheConfig struct {
+	// MaxCost là tổng dung lượng (byte, tính theo kích thước response đã
+	// serialize) cho mọi shard; mỗi shard nhận MaxCost / số shard.
+	MaxCost int64
+	// TTL của mỗi kết quả. Hết hạn -> query lại DB (Eventual Consistency).
+	TTL time.Duration
+	// NumCounters cho mỗi shard, nên gấp ~10 lần số key mong muốn.
+	// 0 = tự tính từ MaxCost.
+	NumCounters int64
+}
+
+const (
+	DefaultCacheMaxCost = 256 << 20 // 256MB
+	DefaultCacheTTL     = 5 * time.Second
+)
+
+// CacheStats là số liệu cộng dồn của cache trên mọi shard.
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Sets   uint64 `json:"sets"`
+}
+
+// resultCache là cache của một shard. Chỉ worker của shard đó đọc/ghi,
+// nhờ sharding theo QueryId nên key nóng luôn nằm ở cache của cùng worker.
+type resultCache struct {
+	c   *ristretto.Cache
+	ttl time.Duration
+}
+
+// Validate reports fields that cannot build a cache. Zero fields take their
+// defaults, so only negative values are rejected.
+func (c CacheConfig) Validate() error {
+	var errs []error
+	if c.MaxCost < 0 {
+		errs = append(errs, errors.New("cache max cost must not be negative"))
+	}
+	if c.TTL < 0 {
+		errs = append(errs, errors.New("cache TTL must not be negative"))
+	}
+	if c.NumCounters < 0 {
+		errs = append(errs, errors.New("cache counters must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// WithResultCache enables the per-shard result cache for SQL jobs. Only
+// read-only queries from the query registry are cached. An invalid cfg makes
+// NewComputeServer return the error from CacheConfig.Validate.
+func WithResultCache(cfg CacheConfig) Option {
+	return func(s *ComputeServer) {
+		// Giá trị âm giữ nguyên để NewComputeServer báo lỗi
+		if cfg.MaxCost == 0 {
+			cfg.MaxCost = DefaultCacheMaxCost
+		}
+		if cfg.TTL == 0 {
+			cfg.TTL = DefaultCacheTTL
+		}
+		s.cacheCfg = &cfg
+	}
+}
+
+// cacheable: chỉ query đã đăng ký và read-only mới được cache. Kết quả của
+// SQL không có trong registry hay của lệnh ghi (INSERT ... RETURNING) không
+// bao giờ được trả lại từ cache.
+func cacheable(q *registry.Query) bool {
+	return q != nil && q.ReadOnly
+}
+
+func newResultCache(cfg CacheConfig, shards int) (*resultCache, error) {
+	maxCost := cfg.MaxCost / int64(shards)
+	if maxCost <= 0 {
+		maxCost = 1
+	}
+	counters := cfg.NumCounters
+	if counters <= 0 {
+		// Giả định response trung bình ~1KB, đếm gấp 10 lần số item.
+		counters = max(maxCost/1024*10, 1000)
+	}
+	c, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: counters,
+		MaxCost:     maxCost,
+		BufferItems: 64,
+		// Cost là số byte của response, không cộng thêm overhead nội bộ.
+		IgnoreInternalCost: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ristretto cache: %w", err)
+	}
+	return &resultCache{c: c, ttl: cfg.TTL}, nil
+}
+
+func (s *ComputeServer) cacheGet(rc *resultCache, key string) (*pb.TestHTTP3Response, bool) {
+	val, found := rc.c.Get(key)
+	if found {
+		if resp, ok := val.(*pb.TestHTTP3Response); ok {
+			s.cacheHits.Add(1)
+			s.metrics.observeCache(true)
+			return resp, true
+		}
+	}
+	s.cacheMisses.Add(1)
+	s.metrics.observeCache(false)
+	return nil, false
+}
+
+func (s *ComputeServer) cacheSet(rc *resultCache, key string, resp *pb.TestHTTP3Response, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = rc.ttl
+	}
+	cost := max(int64(proto.Size(resp)), 1)
+	if rc.c.SetWithTTL(key, resp, cost, ttl) {
+		s.cacheSets.Add(1)
+	}
+}
+
+// CacheStats returns hit/miss counters of the result cache. All zero when the
+// cache is disabled.
+func (s *ComputeServer) CacheStats() CacheStats {
+	return CacheStats{
+		Hits:   s.cacheHits.Load(),
+		Misses: s.cacheMisses.Load(),
+		Sets:   s.cacheSets.Load(),
+	}
+}
+
+// cacheKey builds the cache key from the normalized SQL and its arguments.
+func cacheKey(query string, args ...any) string {
+	var b strings.Builder
+	b.WriteString(registry.Normalize(query))
+	for _, a := range args {
+		fmt.Fprintf(&b, "\x00%T:%v", a, a)
+	}
+	return b.String()
+}
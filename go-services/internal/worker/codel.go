@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"math"
+	"time"
+)
+
+// Tham số mặc định của CoDel. Target là độ trễ hàng đợi chấp nhận được,
+// Interval là khoảng thời gian độ trễ phải liên tục vượt target mới bắt đầu drop.
+const (
+	DefaultCoDelTarget   = 5 * time.Millisecond
+	DefaultCoDelInterval = 100 * time.Millisecond
+)
+
+// coDel là Controlled Delay (Nichols & Jacobson, RFC 8289) áp dụng cho job:
+// luôn FIFO, đo sojourn time (now - EnqueuedAt) của job lấy ra; khi sojourn
+// vượt target liên tục trong interval thì drop, rồi drop dày dần theo
+// interval/sqrt(count) cho tới khi độ trễ về dưới target.
+type coDel struct {
+	localQueue
+	target, interval time.Duration
+
+	firstAboveTime time.Time // zero: sojourn đang dưới target
+	dropNext       time.Time
+	count          int // số job đã drop trong đợt dropping hiện tại
+	lastCount      int
+	dropping       bool
+}
+
+// NewCoDel returns a Controlled Delay policy. Jobs run in FIFO order; once
+// their queueing delay stays above target for a whole interval the policy
+// drops jobs at an increasing rate until the delay falls below target.
+// Non-positive values fall back to DefaultCoDelTarget and DefaultCoDelInterval.
+func NewCoDel(target, interval time.Duration) QueuePolicy {
+	if target <= 0 {
+		target = DefaultCoDelTarget
+	}
+	if interval <= 0 {
+		interval = DefaultCoDelInterval
+	}
+	return &coDel{target: target, interval: interval}
+}
+
+func (p *coDel) Next(now time.Time) QueueDecision {
+	var d QueueDecision
+	wasDropping := p.dropping
+
+	job, okToDrop := p.dequeue(now)
+	switch {
+	case job == nil:
+		p.dropping = false
+	case p.dropping:
+		if !okToDrop {
+			// Độ trễ đã về dưới target: thoát trạng thái dropping
+			p.dropping = false
+			break
+		}
+		for !now.Before(p.dropNext) && p.dropping {
+			d.Dropped = append(d.Dropped, job)
+			p.count++
+			job, okToDrop = p.dequeue(now)
+			if !okToDrop {
+				p.dropping = false
+			} else {
+				p.dropNext = p.controlLaw(p.dropNext)
+			}
+		}
+	case okToDrop:
+		d.Dropped = append(d.Dropped, job)
+		job, _ = p.dequeue(now)
+		p.dropping = true
+		// Vừa thoát dropping không lâu: tiếp tục từ tốc độ drop gần đây
+		delta := p.count - p.lastCount
+		if delta > 1 && now.Sub(p.dropNext) < 16*p.interval {
+			p.count = delta
+		} else {
+			p.count = 1
+		}
+		p.lastCount = p.count
+		p.dropNext = p.controlLaw(now)
+	}
+	d.Job = job
+
+	if p.dropping != wasDropping {
+		mode := "FIFO"
+		if p.dropping {
+			mode = "CODEL_DROP"
+		}
+		d.Modes = append(d.Modes, ModeChange{Mode: mode, QueueLen: p.Len()})
+	}
+	return d
+}
+
+// dequeue lấy job tiếp theo và cho biết có được phép drop nó không.
+func (p *coDel) dequeue(now time.Time) (*Job, bool) {
+	job := p.pop()
+	if job == nil {
+		p.firstAboveTime = time.Time{}
+		return nil, false
+	}
+	// Hàng đợi gần rỗng thì không drop dù job đã chờ lâu
+	if now.Sub(job.EnqueuedAt) < p.target || p.Len() == 0 {
+		p.firstAboveTime = time.Time{}
+		return job, false
+	}
+	if p.firstAboveTime.IsZero() {
+		p.firstAboveTime = now.Add(p.interval)
+		return job, false
+	}
+	return job, !now.Before(p.firstAboveTime)
+}
+
+func (p *coDel) controlLaw(t time.Time) time.Time {
+	return t.Add(time.Duration(float64(p.interval) / math.Sqrt(float64(p.count))))
+}
@@ -0,0 +1,193 @@
+package worker
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const (
+	testTarget   = 5 * time.Millisecond
+	testInterval = 100 * time.Millisecond
+)
+
+// t0 là mốc của đồng hồ giả; CoDel chỉ đọc thời gian qua tham số now của Next.
+var t0 = time.Unix(1_700_000_000, 0)
+
+func ms(n float64) time.Time {
+	return t0.Add(time.Duration(n * float64(time.Millisecond)))
+}
+
+// newTestCoDel trả về CoDel có n job cùng vào hàng lúc t0.
+func newTestCoDel(n int) *coDel {
+	p := NewCoDel(testTarget, testInterval).(*coDel)
+	pushAt(p, t0, n)
+	return p
+}
+
+func pushAt(p *coDel, at time.Time, n int) {
+	for i := 0; i < n; i++ {
+		p.Push(&Job{EnqueuedAt: at})
+	}
+}
+
+// next gọi p.Next(now) và kiểm tra số job bị drop và job được chạy.
+func next(t *testing.T, p *coDel, now time.Time, wantDropped int) QueueDecision {
+	t.Helper()
+	d := p.Next(now)
+	if len(d.Dropped) != wantDropped {
+		t.Fatalf("Next(+%v) dropped %d jobs, want %d", now.Sub(t0), len(d.Dropped), wantDropped)
+	}
+	if d.Job == nil {
+		t.Fatalf("Next(+%v) returned no job", now.Sub(t0))
+	}
+	return d
+}
+
+func wantMode(t *testing.T, d QueueDecision, mode string) {
+	t.Helper()
+	if len(d.Modes) != 1 || d.Modes[0].Mode != mode {
+		t.Fatalf("mode changes = %+v, want one switch to %s", d.Modes, mode)
+	}
+}
+
+func TestCoDelBelowTargetNeverDrops(t *testing.T) {
+	p := newTestCoDel(10)
+	for i := 0; i < 5; i++ {
+		d := next(t, p, ms(4), 0)
+		if len(d.Modes) != 0 {
+			t.Fatalf("mode changes = %+v, want none", d.Modes)
+		}
+	}
+	if !p.firstAboveTime.IsZero() {
+		t.Fatalf("firstAboveTime = %v, want zero while sojourn is below target", p.firstAboveTime)
+	}
+}
+
+func TestCoDelFirstAboveTime(t *testing.T) {
+	p := newTestCoDel(10)
+
+	// Lần đầu vượt target chỉ hẹn giờ, chưa drop
+	next(t, p, ms(10), 0)
+	if want := ms(10).Add(testInterval); !p.firstAboveTime.Equal(want) {
+		t.Fatalf("firstAboveTime = +%v, want +%v", p.firstAboveTime.Sub(t0), want.Sub(t0))
+	}
+	// Vẫn trên target nhưng chưa hết interval: giữ nguyên mốc, không drop
+	next(t, p, ms(109), 0)
+	if p.dropping {
+		t.Fatal("dropping before a whole interval above target")
+	}
+
+	// Sojourn về dưới target thì mốc bị xoá
+	q := NewCoDel(testTarget, testInterval).(*coDel)
+	pushAt(q, ms(100), 2)
+	next(t, q, ms(110), 0)
+	pushAt(q, ms(111), 2)
+	next(t, q, ms(112), 0) // job cũ cuối cùng, vẫn trên target
+	if q.firstAboveTime.IsZero() {
+		t.Fatal("firstAboveTime not set after sojourn above target")
+	}
+	next(t, q, ms(113), 0) // job mới, chờ 2ms
+	if !q.firstAboveTime.IsZero() {
+		t.Fatalf("firstAboveTime = +%v, want zero once sojourn is below target", q.firstAboveTime.Sub(t0))
+	}
+}
+
+func TestCoDelDropSchedule(t *testing.T) {
+	p := newTestCoDel(30)
+
+	next(t, p, ms(10), 0)
+	d := next(t, p, ms(110), 1)
+	wantMode(t, d, "CODEL_DROP")
+	if !p.dropping || p.count != 1 {
+		t.Fatalf("dropping=%v count=%d after entering, want true 1", p.dropping, p.count)
+	}
+	if want := ms(110).Add(testInterval); !p.dropNext.Equal(want) {
+		t.Fatalf("dropNext = +%v, want +%v", p.dropNext.Sub(t0), want.Sub(t0))
+	}
+
+	// Giữa hai lần drop: chạy job bình thường, không đổi chế độ
+	d = next(t, p, ms(150), 0)
+	if len(d.Modes) != 0 {
+		t.Fatalf("mode changes = %+v, want none while dropping", d.Modes)
+	}
+
+	// Sau lần drop thứ count, lần kế tiếp hẹn sau interval/sqrt(count)
+	for count := 2; count <= 5; count++ {
+		at := p.dropNext
+		next(t, p, at, 1)
+		if p.count != count {
+			t.Fatalf("count = %d, want %d", p.count, count)
+		}
+		gap := time.Duration(float64(testInterval) / math.Sqrt(float64(count)))
+		if want := at.Add(gap); !p.dropNext.Equal(want) {
+			t.Fatalf("count %d: dropNext = +%v, want +%v (interval/sqrt(%d))", count, p.dropNext.Sub(t0), want.Sub(t0), count)
+		}
+	}
+
+	// Trễ nhiều lịch drop: drop bù mọi lần đã tới hạn trong một lần Next
+	at := p.dropNext
+	late := at.Add(time.Duration(float64(testInterval)/math.Sqrt(6)) + time.Millisecond)
+	next(t, p, late, 2)
+	if p.count != 7 {
+		t.Fatalf("count = %d after catching up, want 7", p.count)
+	}
+}
+
+func TestCoDelExitsWhenQueueDrains(t *testing.T) {
+	p := newTestCoDel(5)
+	next(t, p, ms(10), 0)
+	next(t, p, ms(110), 1) // còn 2 job
+	next(t, p, ms(120), 0)
+	// Job cuối: hàng đợi rỗng sau khi lấy nên không drop và thoát dropping
+	d := next(t, p, ms(130), 0)
+	wantMode(t, d, "FIFO")
+	if p.dropping {
+		t.Fatal("still dropping after the queue drained")
+	}
+	if d := p.Next(ms(150)); d.Job != nil || len(d.Dropped) != 0 {
+		t.Fatalf("Next on empty queue = %+v, want empty decision", d)
+	}
+}
+
+func TestCoDelReentry(t *testing.T) {
+	tests := []struct {
+		name      string
+		idle      time.Duration // từ lúc thoát tới lần vượt target tiếp theo
+		wantCount int
+	}{
+		// Quay lại sớm: tiếp tục từ tốc độ drop gần nhất (count - lastCount)
+		{"soon after exit resumes rate", 50 * time.Millisecond, 3},
+		// Quá 16 interval: bắt đầu lại từ 1
+		{"long after exit starts over", 2 * time.Second, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestCoDel(10)
+			next(t, p, ms(10), 0)
+			next(t, p, ms(110), 1)
+			for i := 0; i < 3; i++ {
+				next(t, p, p.dropNext, 1)
+			}
+			if p.count != 4 || p.lastCount != 1 {
+				t.Fatalf("count=%d lastCount=%d, want 4 1", p.count, p.lastCount)
+			}
+			// Còn 1 job: lấy nó làm hàng đợi rỗng nên thoát dropping
+			exit := p.dropNext.Add(-time.Millisecond)
+			wantMode(t, next(t, p, exit, 0), "FIFO")
+
+			again := exit.Add(tt.idle)
+			pushAt(p, again.Add(-time.Second), 5)
+			next(t, p, again, 0)
+			d := next(t, p, again.Add(testInterval), 1)
+			wantMode(t, d, "CODEL_DROP")
+			if p.count != tt.wantCount || p.lastCount != tt.wantCount {
+				t.Fatalf("count=%d lastCount=%d after re-entry, want %d", p.count, p.lastCount, tt.wantCount)
+			}
+			gap := time.Duration(float64(testInterval) / math.Sqrt(float64(tt.wantCount)))
+			if want := again.Add(testInterval + gap); !p.dropNext.Equal(want) {
+				t.Fatalf("dropNext = +%v, want +%v", p.dropNext.Sub(t0), want.Sub(t0))
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github/shieldx-bot/laminar/config"
+	"github/shieldx-bot/laminar/internal/registry"
+)
+
+// ConfigOptions translates the worker and registry sections of cfg into
+// options for NewComputeServer, loading the query registry file if set.
+func ConfigOptions(cfg *config.Config) ([]Option, error) {
+	w := cfg.Worker
+	opts := []Option{
+		WithShards(w.Shards),
+		WithInboxSize(w.InboxSize),
+		WithLoadFactor(w.LoadFactor),
+		WithVirtualNodes(w.VirtualNodes),
+		WithTuning(TuningFromConfig(cfg)),
+	}
+
+	switch w.QueuePolicy {
+	case "", "adaptive-lifo":
+		// Policy mặc định, lấy watermark từ Tuning
+	case "codel":
+		opts = append(opts, WithQueuePolicy(func() QueuePolicy {
+			return NewCoDel(time.Duration(w.CoDelTarget), time.Duration(w.CoDelInterval))
+		}))
+	default:
+		return nil, fmt.Errorf("unknown queue policy %q", w.QueuePolicy)
+	}
+
+	if w.CacheTTL > 0 {
+		opts = append(opts, WithResultCache(CacheConfig{TTL: time.Duration(w.CacheTTL), MaxCost: w.CacheMaxCost}))
+	}
+	if w.AdaptiveLimit {
+		opts = append(opts, WithConcurrencyLimiter(LimiterConfig{}))
+	}
+
+	if path := cfg.Registry.Path; path != "" {
+		reg, err := registry.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithQueryRegistry(reg))
+		slog.Info("query registry loaded", slog.Int("queries", reg.Len()), slog.String("path", path))
+	}
+	if cfg.Registry.AllowAdHoc {
+		opts = append(opts, WithAdHocSQL(true))
+		slog.Warn("ad-hoc SQL is enabled: clients may run statements outside the query registry")
+	}
+	return opts, nil
+}
+
+// TuningFromConfig returns the runtime-tunable part of the worker section.
+func TuningFromConfig(cfg *config.Config) Tuning {
+	w := cfg.Worker
+	return Tuning{
+		HighWaterMark:  w.HighWaterMark,
+		LowWaterMark:   w.LowWaterMark,
+		MaxInboxDepth:  w.MaxInboxDepth,
+		StealThreshold: w.StealThreshold,
+		MaxQueueAge:    time.Duration(w.MaxQueueAge),
+	}
+}
+
+// ReloadOnSIGHUP re-reads the configuration with load on every SIGHUP and
+// applies its tuning until ctx is done. A config that fails to load or
+// validate is logged and the running tuning is kept. Settings outside Tuning
+// still need a restart.
+func (s *ComputeServer) ReloadOnSIGHUP(ctx context.Context, load func() (*config.Config, error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+		cfg, err := load()
+		if err != nil {
+			s.tuning.rejected.Add(1)
+			s.log.Error("config reload failed, keeping current tuning", slog.Any("error", err))
+			continue
+		}
+		next := TuningFromConfig(cfg)
+		if _, _, err := s.UpdateTuning(func(t *Tuning) { *t = next }); err != nil {
+			s.log.Error("config reload failed, keeping current tuning", slog.Any("error", err))
+		}
+	}
+}
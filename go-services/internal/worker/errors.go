@@ -0,0 +1,190 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github/shieldx-bot/laminar/errinfo"
+)
+
+// ErrorDomain là domain trong ErrorInfo của mọi lỗi do ComputeServer trả về.
+const ErrorDomain = errinfo.Domain
+
+// Reason trong ErrorInfo, ổn định để client dựa vào. Định nghĩa ở package
+// errinfo để client SDK dùng chung.
+const (
+	ReasonShardQueueFull   = errinfo.ShardQueueFull
+	ReasonConcurrencyLimit = errinfo.ConcurrencyLimit
+	ReasonShuttingDown     = errinfo.ShuttingDown
+	ReasonExpiredInQueue   = errinfo.ExpiredInQueue
+	ReasonDroppedByPolicy  = errinfo.DroppedByPolicy
+	ReasonDBUnavailable    = errinfo.DBUnavailable
+	ReasonInvalidQuery     = errinfo.InvalidQuery
+	ReasonQueryRejected    = errinfo.QueryRejected
+	ReasonConflict         = errinfo.Conflict
+	ReasonDeadline         = errinfo.Deadline
+	ReasonCanceled         = errinfo.Canceled
+	ReasonInternal         = errinfo.Internal
+)
+
+// Thời gian chờ gợi ý (RetryInfo) cho các lỗi nên thử lại.
+const (
+	retryOverloaded = 100 * time.Millisecond
+	retryDropped    = 200 * time.Millisecond
+	retryConflict   = 50 * time.Millisecond
+	retryDBDown     = time.Second
+	retryShutdown   = time.Second
+)
+
+// ErrOverloaded trả về khi không shard nào còn chỗ nhận job.
+var ErrOverloaded = newStatusError(codes.ResourceExhausted, ReasonShardQueueFull, "server overloaded, please retry later", retryOverloaded)
+
+// newStatusError tạo lỗi gRPC kèm ErrorInfo và, nếu retryAfter > 0, RetryInfo.
+func newStatusError(code codes.Code, reason, msg string, retryAfter time.Duration, meta ...string) error {
+	info := &errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain}
+	for i := 0; i+1 < len(meta); i += 2 {
+		if info.Metadata == nil {
+			info.Metadata = make(map[string]string)
+		}
+		info.Metadata[meta[i]] = meta[i+1]
+	}
+	details := []protoadapt.MessageV1{info}
+	if retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+
+	st := status.New(code, msg)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// Classify converts an error from the queue, the driver or Postgres into a
+// gRPC status error with ErrorInfo and, for retryable failures, RetryInfo.
+// Errors that already carry a status are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return newStatusError(codes.DeadlineExceeded, ReasonDeadline, err.Error(), 0)
+	case errors.Is(err, context.Canceled):
+		return newStatusError(codes.Canceled, ReasonCanceled, err.Error(), 0)
+	case errors.As(err, &pqErr):
+		return classifyPQ(pqErr)
+	case isConnError(err):
+		return newStatusError(codes.Unavailable, ReasonDBUnavailable, "database unavailable: "+err.Error(), retryDBDown)
+	}
+	return newStatusError(codes.Internal, ReasonInternal, err.Error(), 0)
+}
+
+// classifyPQ ánh xạ SQLSTATE của Postgres sang mã gRPC.
+func classifyPQ(e *pq.Error) error {
+	code := string(e.Code)
+	msg := e.Error()
+	switch {
+	case code == "42501", code == "25006":
+		// insufficient_privilege, read_only_sql_transaction (query read-only của registry)
+		return newStatusError(codes.PermissionDenied, ReasonQueryRejected, msg, 0, "sqlstate", code)
+	case e.Code.Class() == "42", e.Code.Class() == "22", e.Code.Class() == "2F":
+		// Lỗi cú pháp, bảng/cột không tồn tại, sai kiểu dữ liệu tham số
+		return newStatusError(codes.InvalidArgument, ReasonInvalidQuery, msg, 0, "sqlstate", code)
+	case e.Code.Class() == "23":
+		return newStatusError(codes.FailedPrecondition, ReasonQueryRejected, msg, 0, "sqlstate", code)
+	case e.Code.Class() == "40":
+		// Deadlock / serialization failure: thử lại là thành công
+		return newStatusError(codes.Aborted, ReasonConflict, msg, retryConflict, "sqlstate", code)
+	case code == "57014":
+		// query_canceled: thường do ctx của request hết hạn
+		return newStatusError(codes.DeadlineExceeded, ReasonDeadline, msg, 0, "sqlstate", code)
+	case e.Code.Class() == "08", e.Code.Class() == "53", e.Code.Class() == "57":
+		// Mất kết nối, DB hết tài nguyên, DB đang tắt/khởi động
+		return newStatusError(codes.Unavailable, ReasonDBUnavailable, msg, retryDBDown, "sqlstate", code)
+	}
+	return newStatusError(codes.Internal, ReasonInternal, msg, 0, "sqlstate", code)
+}
+
+func isConnError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryAfter returns the backoff suggested by the RetryInfo detail of err.
+func RetryAfter(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			return ri.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// ErrorReason returns the ErrorInfo reason of err, "" if it has none.
+func ErrorReason(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+	for _, d := range st.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			return ei.GetReason()
+		}
+	}
+	return ""
+}
+
+// HTTPStatus maps the gRPC code of err to the HTTP status the proxies return.
+func HTTPStatus(err error) int {
+	switch status.Code(err) {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
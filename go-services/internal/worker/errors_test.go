@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassify(t *testing.T) {
+	pqErr := func(code string) error {
+		return fmt.Errorf("query: %w", &pq.Error{Code: pq.ErrorCode(code), Message: "boom"})
+	}
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+		retry  time.Duration // 0 = không có RetryInfo
+	}{
+		// Lỗi của hàng đợi đã mang status và được giữ nguyên
+		{"shard queue full", ErrOverloaded, codes.ResourceExhausted, ReasonShardQueueFull, retryOverloaded},
+		{"concurrency limit", ErrLimitExceeded, codes.ResourceExhausted, ReasonConcurrencyLimit, retryOverloaded},
+		{"shutting down", ErrShuttingDown, codes.Unavailable, ReasonShuttingDown, retryShutdown},
+		{"expired in queue", ErrExpiredInQueue, codes.Unavailable, ReasonExpiredInQueue, retryDropped},
+		{"dropped by policy", ErrDroppedByPolicy, codes.Unavailable, ReasonDroppedByPolicy, retryDropped},
+
+		{"deadline", fmt.Errorf("scan: %w", context.DeadlineExceeded), codes.DeadlineExceeded, ReasonDeadline, 0},
+		{"canceled", context.Canceled, codes.Canceled, ReasonCanceled, 0},
+		{"bad conn", driver.ErrBadConn, codes.Unavailable, ReasonDBUnavailable, retryDBDown},
+		{"conn done", sql.ErrConnDone, codes.Unavailable, ReasonDBUnavailable, retryDBDown},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, codes.Unavailable, ReasonDBUnavailable, retryDBDown},
+		{"unknown error", errors.New("boom"), codes.Internal, ReasonInternal, 0},
+
+		// SQLSTATE của Postgres
+		{"insufficient privilege", pqErr("42501"), codes.PermissionDenied, ReasonQueryRejected, 0},
+		{"write in read-only tx", pqErr("25006"), codes.PermissionDenied, ReasonQueryRejected, 0},
+		{"syntax error", pqErr("42601"), codes.InvalidArgument, ReasonInvalidQuery, 0},
+		{"bad parameter", pqErr("22P02"), codes.InvalidArgument, ReasonInvalidQuery, 0},
+		{"unique violation", pqErr("23505"), codes.FailedPrecondition, ReasonQueryRejected, 0},
+		{"serialization failure", pqErr("40001"), codes.Aborted, ReasonConflict, retryConflict},
+		{"deadlock", pqErr("40P01"), codes.Aborted, ReasonConflict, retryConflict},
+		{"statement timeout", pqErr("57014"), codes.DeadlineExceeded, ReasonDeadline, 0},
+		{"connection failure", pqErr("08006"), codes.Unavailable, ReasonDBUnavailable, retryDBDown},
+		{"too many connections", pqErr("53300"), codes.Unavailable, ReasonDBUnavailable, retryDBDown},
+		{"admin shutdown", pqErr("57P01"), codes.Unavailable, ReasonDBUnavailable, retryDBDown},
+		{"internal error", pqErr("XX000"), codes.Internal, ReasonInternal, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Classify(tt.err)
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("code = %v, want %v", got, tt.code)
+			}
+			if got := ErrorReason(err); got != tt.reason {
+				t.Errorf("reason = %q, want %q", got, tt.reason)
+			}
+			retry, ok := RetryAfter(err)
+			if ok != (tt.retry > 0) || retry != tt.retry {
+				t.Errorf("RetryAfter = %v, %v, want %v", retry, ok, tt.retry)
+			}
+		})
+	}
+	if err := Classify(nil); err != nil {
+		t.Fatalf("Classify(nil) = %v, want nil", err)
+	}
+}
+
+func TestClassifyPQKeepsSQLState(t *testing.T) {
+	err := Classify(&pq.Error{Code: "23505", Message: "duplicate key"})
+	st, _ := status.FromError(err)
+	for _, d := range st.Details() {
+		if ei, ok := d.(*errdetails.ErrorInfo); ok {
+			if ei.GetDomain() != ErrorDomain || ei.GetMetadata()["sqlstate"] != "23505" {
+				t.Fatalf("ErrorInfo = %v, want domain %s and sqlstate 23505", ei, ErrorDomain)
+			}
+			return
+		}
+	}
+	t.Fatal("no ErrorInfo detail")
+}
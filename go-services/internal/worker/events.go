@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"time"
+
+	"github/shieldx-bot/laminar/internal/events"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// publishResult reports a finished job. work is the ProcessSingle response,
+// nil for SQL jobs.
+func (s *ComputeServer) publishResult(shard int, job *Job, kind string, work *pb.WorkResponse, err error) {
+	if !s.events.Active(events.TopicResults) {
+		return
+	}
+	ev := events.Event{Shard: shard, Kind: kind}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	msg := events.NewMessage(job.QueryId, err == nil, ev)
+	msg.ServerProcessingTimeNs = time.Since(job.EnqueuedAt).Nanoseconds()
+	if work != nil {
+		msg.QueueWaitTimeNs = work.GetQueueWaitTimeNs()
+		msg.ServiceTimeNs = work.GetServiceTimeNs()
+	}
+	s.events.Publish(events.TopicResults, msg)
+}
+
+func (s *ComputeServer) publishMode(shard int, c ModeChange) {
+	if !s.events.Active(events.TopicQueueMode) {
+		return
+	}
+	s.events.Publish(events.TopicQueueMode, events.NewMessage("", true, events.Event{
+		Shard:    shard,
+		Priority: c.Priority,
+		Mode:     c.Mode,
+		QueueLen: c.QueueLen,
+	}))
+}
+
+func (s *ComputeServer) publishOverload(shard int, job *Job, queueLen int) {
+	if !s.events.Active(events.TopicOverload) {
+		return
+	}
+	s.events.Publish(events.TopicOverload, events.NewMessage(job.QueryId, false, events.Event{
+		Shard:    shard,
+		QueueLen: queueLen,
+		Reason:   "shard queue full",
+	}))
+}
@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeResult là kết quả một query của fakeDB: cột INT8 "n" với các giá trị 1..rows.
+type fakeResult struct {
+	rows  int
+	delay time.Duration // giả lập thời gian chạy; bị huỷ theo ctx
+	err   error
+}
+
+// fakeDB là driver database/sql trong bộ nhớ cho test worker, không cần Postgres.
+type fakeDB struct {
+	handle  func(query string, args []driver.NamedValue) fakeResult
+	queries atomic.Int64
+	// readOnlyTx đếm transaction READ ONLY đã commit
+	readOnlyTx atomic.Int64
+}
+
+// newFakeDB trả về *sql.DB chạy mọi query qua handle.
+func newFakeDB(t *testing.T, handle func(query string, args []driver.NamedValue) fakeResult) (*sql.DB, *fakeDB) {
+	t.Helper()
+	f := &fakeDB{handle: handle}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { db.Close() })
+	return db, f
+}
+
+// rowsDB trả về fakeDB mà mọi query trả n dòng ngay lập tức.
+func rowsDB(t *testing.T, n int) (*sql.DB, *fakeDB) {
+	return newFakeDB(t, func(string, []driver.NamedValue) fakeResult { return fakeResult{rows: n} })
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use sql.OpenDB") }
+
+type fakeConn struct {
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *fakeConn) Close() error { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.tx = &fakeTx{conn: c, readOnly: opts.ReadOnly}
+	return c.tx, nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.queries.Add(1)
+	res := c.db.handle(query, args)
+	if res.delay > 0 {
+		t := time.NewTimer(res.delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeRows{conn: c, total: res.rows}, nil
+}
+
+type fakeTx struct {
+	conn     *fakeConn
+	readOnly bool
+	open     *fakeRows // rows chưa đóng của transaction
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.conn.tx = nil
+	// Giống Postgres: không commit được khi còn statement đang đọc dở
+	if tx.open != nil && !tx.open.closed {
+		return errors.New("fakedb: commit with unfinished rows")
+	}
+	if tx.readOnly {
+		tx.conn.db.readOnlyTx.Add(1)
+	}
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+type fakeRows struct {
+	conn   *fakeConn
+	total  int
+	next   int
+	closed bool
+}
+
+func (r *fakeRows) Columns() []string                     { return []string{"n"} }
+func (r *fakeRows) ColumnTypeDatabaseTypeName(int) string { return "INT8" }
+
+func (r *fakeRows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if tx := r.conn.tx; tx != nil {
+		tx.open = r
+	}
+	if r.next >= r.total {
+		return io.EOF
+	}
+	r.next++
+	dest[0] = int64(r.next)
+	return nil
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ShardInfo là ảnh chụp trạng thái một shard cho admin API. Các giá trị đọc
+// không cùng một thời điểm nên chỉ dùng để chẩn đoán, không để tính toán.
+type ShardInfo struct {
+	ID int `json:"id"`
+	// Inbox là số job trong channel chưa được worker hút vào local queue.
+	Inbox  int `json:"inbox"`
+	Queued int `json:"queued"`
+	// Load = job trong inbox, local queue và đang chạy.
+	Load int64 `json:"load"`
+	// Modes là chế độ hàng đợi của từng lớp ưu tiên, theo chỉ số Priority.
+	Modes []string `json:"modes"`
+	// OldestJobAge là thời gian chờ của job cũ nhất trong local queue (0 khi rỗng).
+	// Job còn trong inbox không tính: chúng đến sau lần hút inbox cuối, tức là
+	// không sớm hơn nhiều so với InFlight.StartedAt.
+	OldestJobAge time.Duration `json:"oldest_job_age"`
+	// InFlight là job worker của shard đang chạy, nil khi rảnh.
+	InFlight *InFlightJob `json:"in_flight,omitempty"`
+}
+
+// InFlightJob mô tả job đang chạy trên một shard.
+type InFlightJob struct {
+	QueryID    string    `json:"query_id"`
+	Kind       string    `json:"kind"` // sql hoặc work
+	Priority   int32     `json:"priority"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	StartedAt  time.Time `json:"started_at"`
+	// Stolen: job được lấy trộm từ shard khác.
+	Stolen bool `json:"stolen"`
+}
+
+// oldestJob được QueuePolicy dựa trên localQueue cài đặt; policy khác thì
+// OldestJobAge luôn là 0.
+type oldestJob interface {
+	Oldest() (time.Time, bool)
+}
+
+// Shards returns a snapshot of every shard currently accepting jobs: queue
+// depth, mode of each priority class, age of the oldest queued job and the
+// job its worker is running.
+func (s *ComputeServer) Shards() []ShardInfo {
+	s.mu.RLock()
+	shards := s.shards
+	s.mu.RUnlock()
+
+	now := time.Now()
+	out := make([]ShardInfo, 0, len(shards))
+	for _, sh := range shards {
+		info := ShardInfo{
+			ID:    sh.id,
+			Inbox: len(sh.inbox),
+			Load:  sh.load.Load(),
+			Modes: make([]string, numPriorities),
+		}
+		for p := range sh.modes {
+			info.Modes[p] = sh.mode(p)
+		}
+
+		sh.mu.Lock()
+		info.Queued = sh.queue.Len()
+		if q, ok := sh.queue.(oldestJob); ok {
+			if t, ok := q.Oldest(); ok {
+				info.OldestJobAge = now.Sub(t)
+			}
+		}
+		sh.mu.Unlock()
+
+		if j := sh.running.Load(); j != nil {
+			job := *j
+			info.InFlight = &job
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// DBStats returns the connection pool stats of the server's database, zero
+// when it runs without one.
+func (s *ComputeServer) DBStats() sql.DBStats {
+	if s.db == nil {
+		return sql.DBStats{}
+	}
+	return s.db.Stats()
+}
@@ -0,0 +1,210 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrLimitExceeded trả về cho query bị từ chối ngay tại cửa vì số query đang
+// chạy đã chạm giới hạn concurrency hiện tại.
+var ErrLimitExceeded = newStatusError(codes.ResourceExhausted, ReasonConcurrencyLimit, "concurrency limit reached, please retry later", retryOverloaded)
+
+// LimiterConfig cấu hình bộ giới hạn concurrency thích ứng trước các shard.
+//
+// Thuật toán kiểu TCP Vegas: latency lúc không tải (noLoadRTT, min các mẫu)
+// cho biết có bao nhiêu query đang phải xếp hàng trong DB:
+// queue = limit * (1 - noLoadRTT / rtt). Hàng đợi ngắn -> tăng limit, dài ->
+// giảm. Query bị drop/timeout -> giảm nhân (AIMD).
+type LimiterConfig struct {
+	InitialLimit int
+	MinLimit     int
+	MaxLimit     int
+	// Smoothing: tỉ lệ limit mới được trộn vào limit cũ mỗi mẫu (0..1].
+	Smoothing float64
+	// BackoffRatio: limit *= BackoffRatio khi một query bị drop hoặc timeout.
+	BackoffRatio float64
+	// ProbeMultiplier: sau ProbeMultiplier * limit mẫu thì hạ limit một nửa
+	// và đo lại noLoadRTT, để theo kịp khi DB nhanh/chậm đi hẳn.
+	ProbeMultiplier int
+}
+
+const (
+	DefaultLimiterInitialLimit    = 20
+	DefaultLimiterMinLimit        = 4
+	DefaultLimiterMaxLimit        = 1000
+	DefaultLimiterSmoothing       = 1.0
+	DefaultLimiterBackoffRatio    = 0.9
+	DefaultLimiterProbeMultiplier = 30
+)
+
+// LimiterStats là trạng thái hiện tại của limiter.
+type LimiterStats struct {
+	Limit    int    `json:"limit"`
+	InFlight int    `json:"in_flight"`
+	Rejected uint64 `json:"rejected"`
+	// NoLoadRTT là latency lúc không tải đang dùng làm mốc.
+	NoLoadRTT time.Duration `json:"no_load_rtt"`
+}
+
+// WithConcurrencyLimiter puts an adaptive concurrency limiter in front of the
+// shards for ExecuteQuery. Queries beyond the current limit fail fast with
+// ErrLimitExceeded instead of queueing. Zero fields take their defaults.
+func WithConcurrencyLimiter(cfg LimiterConfig) Option {
+	return func(s *ComputeServer) {
+		s.limiter = newLimiter(cfg)
+	}
+}
+
+// LimiterStats returns the limiter state, all zero when it is disabled.
+func (s *ComputeServer) LimiterStats() LimiterStats {
+	if s.limiter == nil {
+		return LimiterStats{}
+	}
+	return s.limiter.stats()
+}
+
+type limiter struct {
+	cfg LimiterConfig
+
+	mu        sync.Mutex
+	limit     float64
+	inFlight  int
+	rejected  uint64
+	noLoadRTT float64 // ns, 0 = chưa có mẫu
+	samples   int     // số mẫu từ lần đo lại noLoadRTT gần nhất
+}
+
+func newLimiter(cfg LimiterConfig) *limiter {
+	if cfg.MinLimit <= 0 {
+		cfg.MinLimit = DefaultLimiterMinLimit
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = DefaultLimiterMaxLimit
+	}
+	if cfg.MaxLimit < cfg.MinLimit {
+		cfg.MaxLimit = cfg.MinLimit
+	}
+	if cfg.InitialLimit <= 0 {
+		cfg.InitialLimit = DefaultLimiterInitialLimit
+	}
+	if cfg.Smoothing <= 0 || cfg.Smoothing > 1 {
+		cfg.Smoothing = DefaultLimiterSmoothing
+	}
+	if cfg.BackoffRatio <= 0 || cfg.BackoffRatio >= 1 {
+		cfg.BackoffRatio = DefaultLimiterBackoffRatio
+	}
+	if cfg.ProbeMultiplier <= 0 {
+		cfg.ProbeMultiplier = DefaultLimiterProbeMultiplier
+	}
+	l := &limiter{cfg: cfg}
+	l.limit = l.clamp(float64(cfg.InitialLimit))
+	return l
+}
+
+// acquire giữ một slot, false nếu đã chạm limit.
+func (l *limiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if float64(l.inFlight) >= l.limit {
+		l.rejected++
+		return false
+	}
+	l.inFlight++
+	return true
+}
+
+// release trả slot và cập nhật limit theo kết quả của query. queried: query
+// đã chạy xong trên DB; chỉ khi đó rtt mới là một mẫu latency của DB.
+func (l *limiter) release(rtt time.Duration, err error, queried bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	inFlight := l.inFlight
+	l.inFlight--
+
+	switch {
+	case errors.Is(err, context.Canceled), status.Code(err) == codes.Canceled:
+		// Client bỏ đi: không nói gì về sức chịu của DB
+	case isOverloadErr(err):
+		l.limit = l.clamp(l.limit * l.cfg.BackoffRatio)
+	case err == nil && queried:
+		l.sample(float64(rtt), inFlight)
+	default:
+		// Cache hit, query bị từ chối (sai tham số, không có trong registry...)
+		// hay lỗi SQL: trả về rất nhanh mà không đo gì về DB, nếu lấy làm mẫu
+		// sẽ kéo noLoadRTT xuống và ép limit về MinLimit
+	}
+}
+
+func (l *limiter) sample(rtt float64, inFlight int) {
+	if rtt <= 0 {
+		return
+	}
+	l.samples++
+	if l.samples > l.cfg.ProbeMultiplier*int(l.limit) {
+		// Đo lại mốc: hạ limit một nửa để DB xả hàng đợi, các mẫu ngay sau
+		// đó cho noLoadRTT mới (giống ProbeRTT của BBR)
+		l.noLoadRTT, l.samples = 0, 0
+		l.limit = l.clamp(l.limit / 2)
+	}
+	if l.noLoadRTT == 0 || rtt < l.noLoadRTT {
+		l.noLoadRTT = rtt
+		return
+	}
+
+	logLimit := math.Max(1, math.Log10(l.limit))
+	alpha, beta := 3*logLimit, 6*logLimit
+	queue := math.Ceil(l.limit * (1 - l.noLoadRTT/rtt))
+
+	newLimit := l.limit
+	switch {
+	case queue > beta:
+		newLimit = l.limit - logLimit
+	case float64(inFlight) < l.limit/2:
+		// Đang dùng chưa tới nửa limit: không có bằng chứng để tăng
+		return
+	case queue <= logLimit:
+		newLimit = l.limit + beta
+	case queue < alpha:
+		newLimit = l.limit + logLimit
+	}
+	l.limit = l.clamp(l.limit*(1-l.cfg.Smoothing) + newLimit*l.cfg.Smoothing)
+}
+
+func (l *limiter) clamp(v float64) float64 {
+	return math.Max(float64(l.cfg.MinLimit), math.Min(float64(l.cfg.MaxLimit), v))
+}
+
+func (l *limiter) stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LimiterStats{
+		Limit:     int(l.limit),
+		InFlight:  l.inFlight,
+		Rejected:  l.rejected,
+		NoLoadRTT: time.Duration(l.noLoadRTT),
+	}
+}
+
+// isOverloadErr báo query bị drop/timeout vì hệ thống quá tải, tín hiệu để
+// giảm limit.
+func isOverloadErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrExpiredInQueue) ||
+		errors.Is(err, ErrDroppedByPolicy) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
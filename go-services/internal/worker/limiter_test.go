@@ -0,0 +1,205 @@
+package worker
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github/shieldx-bot/laminar/internal/registry"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+const noLoad = 10 * time.Millisecond
+
+// rttForQueue trả về rtt sao cho queue = limit * (1 - noLoad/rtt) bằng queue.
+func rttForQueue(limit, queue float64) time.Duration {
+	return time.Duration(float64(noLoad) / (1 - queue/limit))
+}
+
+func TestLimiterGradient(t *testing.T) {
+	// limit 100: logLimit = 2, alpha = 6, beta = 12
+	tests := []struct {
+		name      string
+		limit     float64
+		smoothing float64
+		rtt       time.Duration
+		inFlight  int
+		want      float64
+	}{
+		{"no queue increases by beta", 100, 1, noLoad, 80, 112},
+		{"short queue increases by log", 100, 1, rttForQueue(100, 3.5), 80, 102},
+		{"queue between alpha and beta holds", 100, 1, rttForQueue(100, 7.5), 80, 100},
+		{"long queue decreases by log", 100, 1, 2 * noLoad, 80, 98},
+		{"long queue decreases even when underused", 100, 1, 2 * noLoad, 10, 98},
+		{"underused does not increase", 100, 1, noLoad, 49, 100},
+		{"smoothing blends old and new limit", 100, 0.5, noLoad, 80, 106},
+		{"clamped at max", 995, 1, noLoad, 900, 1000},
+		{"clamped at min", 4, 1, 10 * noLoad, 4, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(LimiterConfig{MinLimit: 4, MaxLimit: 1000, Smoothing: tt.smoothing, ProbeMultiplier: 1000})
+			l.limit, l.noLoadRTT = tt.limit, float64(noLoad)
+			l.sample(float64(tt.rtt), tt.inFlight)
+			if math.Abs(l.limit-tt.want) > 1e-9 {
+				t.Errorf("limit after sample(rtt=%v, inFlight=%d) = %v, want %v", tt.rtt, tt.inFlight, l.limit, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterNoLoadRTT(t *testing.T) {
+	l := newLimiter(LimiterConfig{InitialLimit: 50})
+	// Mẫu đầu tiên và mẫu nhanh hơn chỉ dời mốc, không đổi limit
+	for _, rtt := range []time.Duration{20 * time.Millisecond, noLoad} {
+		l.sample(float64(rtt), 50)
+		if l.noLoadRTT != float64(rtt) || l.limit != 50 {
+			t.Fatalf("after sample(%v): noLoadRTT=%v limit=%v, want %v 50", rtt, time.Duration(l.noLoadRTT), l.limit, rtt)
+		}
+	}
+}
+
+func TestLimiterProbeHalvesLimit(t *testing.T) {
+	l := newLimiter(LimiterConfig{InitialLimit: 40, ProbeMultiplier: 1})
+	l.noLoadRTT = float64(noLoad)
+	for i := 0; i < 40; i++ {
+		l.sample(float64(rttForQueue(l.limit, 7.5)), 0)
+	}
+	if l.limit != 40 || l.samples != 40 {
+		t.Fatalf("limit=%v samples=%d before probe, want 40 40", l.limit, l.samples)
+	}
+	// Mẫu thứ limit*ProbeMultiplier+1: hạ một nửa và đo lại mốc từ mẫu này
+	l.sample(float64(2*noLoad), 0)
+	if l.limit != 20 || l.noLoadRTT != float64(2*noLoad) || l.samples != 0 {
+		t.Fatalf("after probe: limit=%v noLoadRTT=%v samples=%d, want 20 %v 0", l.limit, time.Duration(l.noLoadRTT), l.samples, 2*noLoad)
+	}
+}
+
+func TestLimiterRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   float64
+		err     error
+		queried bool
+		want    float64
+	}{
+		{"deadline backs off", 100, context.DeadlineExceeded, false, 90},
+		{"expired in queue backs off", 100, ErrExpiredInQueue, false, 90},
+		{"dropped by policy backs off", 100, ErrDroppedByPolicy, false, 90},
+		{"resource exhausted backs off", 100, status.Error(codes.ResourceExhausted, "busy"), false, 90},
+		{"backoff clamped at min", 4, context.DeadlineExceeded, false, 4},
+		{"canceled is ignored", 100, context.Canceled, false, 100},
+		{"canceled status is ignored", 100, status.Error(codes.Canceled, "gone"), false, 100},
+		{"query errors are ignored", 100, fmt.Errorf("syntax error"), true, 100},
+		{"rejected before the DB is ignored", 100, status.Error(codes.InvalidArgument, "bad args"), false, 100},
+		{"cache hit is ignored", 100, nil, false, 100},
+		{"query that ran is a sample", 100, nil, true, 112},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(LimiterConfig{MinLimit: 4, ProbeMultiplier: 1000})
+			l.limit, l.noLoadRTT = tt.limit, float64(noLoad)
+			l.inFlight = int(tt.limit)
+			l.release(noLoad, tt.err, tt.queried)
+			if math.Abs(l.limit-tt.want) > 1e-9 {
+				t.Errorf("limit after release(%v, queried=%v) = %v, want %v", tt.err, tt.queried, l.limit, tt.want)
+			}
+			if l.inFlight != int(tt.limit)-1 {
+				t.Errorf("inFlight = %d, want %d", l.inFlight, int(tt.limit)-1)
+			}
+		})
+	}
+}
+
+func TestLimiterIgnoresFastNonQueries(t *testing.T) {
+	l := newLimiter(LimiterConfig{InitialLimit: 100, ProbeMultiplier: 1000})
+	l.noLoadRTT = float64(noLoad)
+
+	// Cache hit và lỗi tham số trả về trong vài µs: không được thành mốc noLoadRTT
+	for i := 0; i < 1000; i++ {
+		l.inFlight = 80
+		if i%2 == 0 {
+			l.release(5*time.Microsecond, nil, false)
+		} else {
+			l.release(5*time.Microsecond, status.Error(codes.NotFound, "unknown query"), false)
+		}
+	}
+	if l.noLoadRTT != float64(noLoad) || l.limit != 100 {
+		t.Fatalf("after fast non-queries: noLoadRTT=%v limit=%v, want %v 100", time.Duration(l.noLoadRTT), l.limit, noLoad)
+	}
+
+	// Query DB bình thường (hơi trên mốc) vẫn không làm limit giảm
+	for i := 0; i < 50; i++ {
+		l.inFlight = 80
+		l.release(noLoad+noLoad/50, nil, true)
+	}
+	if l.limit < 100 {
+		t.Fatalf("limit = %v after normal DB queries, want at least 100", l.limit)
+	}
+}
+
+func TestExecuteQuerySamplesOnlyDBQueries(t *testing.T) {
+	db, _ := newFakeDB(t, func(string, []driver.NamedValue) fakeResult {
+		return fakeResult{rows: 1, delay: 2 * time.Millisecond}
+	})
+	reg := mustRegistry(t, &registry.Query{Name: "one", SQL: "SELECT 1", ReadOnly: true})
+	s := newTestServer(t, db, WithQueryRegistry(reg), WithConcurrencyLimiter(LimiterConfig{}))
+
+	// Query không có trong registry bị từ chối trước khi tới DB
+	for i := 0; i < 100; i++ {
+		if _, err := s.ExecuteQuery(context.Background(), &pb.TestHTTP3Request{QueryName: "missing"}); status.Code(err) != codes.NotFound {
+			t.Fatalf("ExecuteQuery(missing) = %v, want NotFound", err)
+		}
+	}
+	if st := s.LimiterStats(); st.NoLoadRTT != 0 {
+		t.Fatalf("NoLoadRTT = %v after rejected queries only, want no sample", st.NoLoadRTT)
+	}
+
+	if _, err := s.ExecuteQuery(context.Background(), &pb.TestHTTP3Request{QueryName: "one"}); err != nil {
+		t.Fatalf("ExecuteQuery(one): %v", err)
+	}
+	if st := s.LimiterStats(); st.NoLoadRTT < 2*time.Millisecond {
+		t.Fatalf("NoLoadRTT = %v, want the DB query's latency (>= 2ms)", st.NoLoadRTT)
+	}
+}
+
+func TestLimiterAcquireAtLimit(t *testing.T) {
+	l := newLimiter(LimiterConfig{InitialLimit: 4, MinLimit: 4})
+	for i := 0; i < 4; i++ {
+		if !l.acquire() {
+			t.Fatalf("acquire %d rejected below the limit", i+1)
+		}
+	}
+	if l.acquire() {
+		t.Fatal("acquire accepted above the limit")
+	}
+	if st := l.stats(); st.InFlight != 4 || st.Rejected != 1 {
+		t.Fatalf("stats = %+v, want 4 in flight and 1 rejected", st)
+	}
+}
+
+func TestNewLimiterClampsConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  LimiterConfig
+		want float64
+	}{
+		{"defaults", LimiterConfig{}, DefaultLimiterInitialLimit},
+		{"initial above max", LimiterConfig{InitialLimit: 50, MaxLimit: 10}, 10},
+		{"initial below min", LimiterConfig{InitialLimit: 2, MinLimit: 8}, 8},
+		{"max below min uses min", LimiterConfig{InitialLimit: 50, MinLimit: 8, MaxLimit: 5}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLimiter(tt.cfg).limit; got != tt.want {
+				t.Errorf("initial limit = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github/shieldx-bot/laminar/logging"
+)
+
+// WithLogger sets the logger of the compute server (default slog.Default()).
+// Records about a job carry its query_id, shard and queue mode, plus the trace
+// id when the logger's handler is built by the logging package.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *ComputeServer) {
+		if l != nil {
+			s.log = l
+		}
+	}
+}
+
+// jobContext gắn query_id, shard và chế độ hàng đợi lúc pop vào ctx của job
+// để mọi dòng log trong lúc xử lý job mang theo.
+func jobContext(job *Job, sh *shard) context.Context {
+	return logging.With(job.Ctx,
+		slog.String(logging.KeyQueryID, job.QueryId),
+		slog.Int(logging.KeyShard, sh.id),
+		slog.String(logging.KeyQueueMode, sh.mode(int(job.Priority))),
+	)
+}
+
+// logReject ghi job bị trả lỗi mà không chạy. Ở level debug vì khi quá tải
+// có thể có hàng nghìn dòng mỗi giây; số lượng đã có ở metric rejections_total.
+func (s *ComputeServer) logReject(shard int, job *Job, err error) {
+	ctx := job.Ctx
+	if !s.log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	s.log.DebugContext(ctx, "job rejected",
+		slog.String(logging.KeyQueryID, job.QueryId),
+		slog.Int(logging.KeyShard, shard),
+		slog.String("reason", ErrorReason(Classify(err))),
+		slog.Any("error", err),
+	)
+}
+
+func (s *ComputeServer) logMode(shard int, c ModeChange) {
+	s.log.Info("queue mode switched",
+		slog.Int(logging.KeyShard, shard),
+		slog.Int("priority", int(c.Priority)),
+		slog.String(logging.KeyQueueMode, c.Mode),
+		slog.Int("queue_len", c.QueueLen),
+	)
+}
@@ -4,12 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"hash/fnv"
+	"log/slog"
 	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github/shieldx-bot/laminar/internal/events"
+	"github/shieldx-bot/laminar/internal/registry"
 	pb "github/shieldx-bot/laminar/pb"
 )
 
@@ -18,134 +27,327 @@ type Job struct {
 	QueryId  string
 	Action   string
 	CT       *pb.TestHTTP3Request
-	RespChan chan *JobResult
+	Work     *pb.WorkRequest // Set instead of CT for DB-free ProcessSingle jobs
+	Priority int32
+	// EnqueuedAt is stamped by dispatch right before the job enters a shard.
+	EnqueuedAt time.Time
+	RespChan   chan *JobResult
 }
 
 type JobResult struct {
 	Resp *pb.TestHTTP3Response
+	Work *pb.WorkResponse
 	Err  error
+	// Queried: query đã chạy xong trên DB. false với cache hit và với job bị
+	// từ chối hay lỗi trước khi tới DB.
+	Queried bool
 }
 
 type ComputeServer struct {
 	pb.UnimplementedLaminarGatewayServer
-	workerChans []chan *Job
-	numShards   int
+	db *sql.DB
+	// shards[i].id == i; đổi khi Resize (giữ s.mu ghi)
+	shards     []*shard
+	numShards  int // số shard lúc khởi tạo
+	inboxSize  int
+	ring       *hashRing
+	loadFactor float64
+	vnodes     int
+
+	events *events.Hub // nil: không publish event
+
+	// Registry các query được phép (query_name -> SQL, read-only, max rows, TTL)
+	registry   *registry.Registry
+	allowAdHoc bool // true: chạy cả SQL không có trong registry (WithAdHocSQL)
+
+	// Cache kết quả riêng của từng shard (nil = tắt)
+	cacheCfg    *CacheConfig
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+	cacheSets   atomic.Uint64
+
+	// mu bảo vệ việc gửi vào inbox khỏi việc đóng channel khi Shutdown/Resize,
+	// và bảo vệ shards, ring
+	mu        sync.RWMutex
+	closing   bool
+	workers   sync.WaitGroup
+	abort     chan struct{} // đóng khi Shutdown hết thời gian chờ
+	abortOnce sync.Once
+
+	// Tham số đổi được lúc runtime (watermark, ngưỡng inbox, steal, MaxQueueAge)
+	tuning tuningState
+
+	// Work stealing: worker rảnh lấy việc ở đuôi local queue của shard có
+	// ít nhất Tuning.StealThreshold job đang chờ
+	stealWake   chan struct{}
+	steals      atomic.Uint64
+	stealMisses atomic.Uint64
+
+	// Tạo QueuePolicy cho mỗi shard (mặc định Adaptive LIFO)
+	newPolicy func() QueuePolicy
+
+	// Giới hạn concurrency thích ứng cho ExecuteQuery (nil = tắt)
+	limiter *limiter
+
+	// Metric Prometheus (nil = tắt), đăng ký vào metricsReg khi khởi tạo
+	metricsReg prometheus.Registerer
+	metrics    *workerMetrics
+
+	log *slog.Logger
 }
 
-type ExampleRecord struct {
-	ID            int    `json:"id"`
-	USERNAME      string `json:"username"`
-	EMAIL         string `json:"email"`
-	PASSWORD_HASH string `json:"password_hash"`
-	BALANCE       int64  `json:"balance"`
-	IS_ACTIVE     bool   `json:"is_active"`
-	CREATED_AT    string `json:"created_at"`
-	UPDATED_AT    string `json:"updated_at"`
+// DefaultMaxQueueAge: quá thời gian này client gần như chắc chắn đã timeout.
+const DefaultMaxQueueAge = 3 * time.Second
+
+// ErrExpiredInQueue trả về cho job bị drop vì chờ trong hàng đợi quá MaxQueueAge.
+var ErrExpiredInQueue = newStatusError(codes.Unavailable, ReasonExpiredInQueue, "job expired in queue", retryDropped)
+
+// Option cấu hình ComputeServer khi khởi tạo.
+type Option func(*ComputeServer)
+
+// WithEventHub publishes worker results, queue mode switches and overload
+// rejections to hub.
+func WithEventHub(hub *events.Hub) Option {
+	return func(s *ComputeServer) {
+		s.events = hub
+	}
 }
 
-func ExecuteSQLQery(query string, db *sql.DB) ([]*structpb.Struct, error) {
-	rows, err := db.Query(query)
-	if err != nil {
-		return nil, err
+// WithShards overrides the number of worker shards (default runtime.NumCPU()).
+func WithShards(n int) Option {
+	return func(s *ComputeServer) {
+		if n > 0 {
+			s.numShards = n
+		}
 	}
-	defer rows.Close()
-	var record []ExampleRecord
-	for rows.Next() {
-		var r ExampleRecord
-		if err := rows.Scan(&r.ID, &r.USERNAME, &r.EMAIL, &r.PASSWORD_HASH, &r.BALANCE, &r.IS_ACTIVE, &r.CREATED_AT, &r.UPDATED_AT); err != nil {
-			return nil, err
+}
+
+// DefaultInboxSize là buffer channel của mỗi shard.
+const DefaultInboxSize = 100
+
+// WithInboxSize sets the channel buffer of each shard (default 100).
+func WithInboxSize(n int) Option {
+	return func(s *ComputeServer) {
+		if n > 0 {
+			s.inboxSize = n
 		}
-		record = append(record, r)
+	}
+}
 
+// WithLoadFactor sets how far above the mean load a shard may go before new
+// keys spill over to the next shard on the hash ring. Values below 1 are
+// treated as 1.
+func WithLoadFactor(f float64) Option {
+	return func(s *ComputeServer) {
+		s.loadFactor = f
 	}
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+// WithVirtualNodes sets how many points each shard owns on the hash ring.
+func WithVirtualNodes(n int) Option {
+	return func(s *ComputeServer) {
+		s.vnodes = n
 	}
+}
 
-	var results []*structpb.Struct
-	for _, r := range record {
-		// structpb only supports JSON-like scalars; normalize ints to float64.
-		rowMap := map[string]interface{}{
-			"id":            float64(r.ID),
-			"username":      r.USERNAME,
-			"email":         r.EMAIL,
-			"password_hash": r.PASSWORD_HASH,
-			"balance":       float64(r.BALANCE),
-			"is_active":     r.IS_ACTIVE,
-			"created_at":    r.CREATED_AT,
-			"updated_at":    r.UPDATED_AT,
-		}
+// WithMaxQueueAge sets how long a job may wait in a shard queue before it is
+// dropped with ErrExpiredInQueue. Zero disables stale-job dropping. It can be
+// changed later with UpdateTuning.
+func WithMaxQueueAge(d time.Duration) Option {
+	return func(s *ComputeServer) {
+		s.setInitial(func(t *Tuning) { t.MaxQueueAge = d })
+	}
+}
 
-		st, err := structpb.NewStruct(rowMap)
+// ExecuteSQLQery chạy query với tham số vị trí ($1, $2, ...) do driver bind,
+// không bao giờ ghép chuỗi giá trị vào SQL. Khi ctx bị huỷ (client timeout),
+// lib/pq gửi cancel request để Postgres dừng query và trả connection về pool.
+func ExecuteSQLQery(ctx context.Context, query string, db *sql.DB, args ...any) ([]*structpb.Struct, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return ScanRows(rows, 0)
+}
+
+// executeResolved chạy query đã resolve, áp dụng read-only và max rows từ
+// registry. truncated = true khi kết quả bị cắt ở MaxRows.
+func executeResolved(ctx context.Context, db *sql.DB, rq resolvedQuery) (records []*structpb.Struct, truncated bool, err error) {
+	if rq.meta == nil || !rq.meta.ReadOnly {
+		rows, err := db.QueryContext(ctx, rq.sql, rq.args...)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
-		results = append(results, st)
+		return ScanRowsLimit(rows, maxRows(rq.meta))
 	}
 
-	return results, nil
+	// Query read-only chạy trong transaction READ ONLY: Postgres từ chối mọi lệnh ghi.
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.QueryContext(ctx, rq.sql, rq.args...)
+	if err != nil {
+		return nil, false, err
+	}
+	// ScanRowsLimit đã đóng rows nên Commit không gặp statement dở dang
+	records, truncated, err = ScanRowsLimit(rows, rq.meta.MaxRows)
+	if err != nil {
+		return nil, false, err
+	}
+	return records, truncated, tx.Commit()
+}
 
+// query chạy rq trong span "postgres.query" con của span worker.
+func (s *ComputeServer) query(ctx context.Context, db *sql.DB, rq resolvedQuery) (records []*structpb.Struct, truncated bool, err error) {
+	ctx, span := tracer.Start(ctx, "postgres.query", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system.name", "postgresql")))
+	defer func() {
+		span.SetAttributes(attribute.Int("db.response.returned_rows", len(records)), attrTruncated.Bool(truncated))
+		endSpan(span, err)
+	}()
+	return executeResolved(ctx, db, rq)
 }
-func NewComputeServer(db *sql.DB) *ComputeServer {
-	numShares := runtime.NumCPU()
 
-	s := &ComputeServer{
-		workerChans: make([]chan *Job, numShares),
-		numShards:   numShares,
+func maxRows(q *registry.Query) int {
+	if q == nil {
+		return 0
 	}
+	return q.MaxRows
+}
 
-	for i := 0; i < numShares; i++ {
-		s.workerChans[i] = make(chan *Job, 100) // Buffer 100 jobs per worker
+// NewComputeServer creates the shards and starts their workers. It returns an
+// error, with no worker started, when an option is invalid, e.g. a negative
+// CacheConfig field.
+func NewComputeServer(db *sql.DB, opts ...Option) (*ComputeServer, error) {
+	s := &ComputeServer{
+		db:         db,
+		numShards:  runtime.NumCPU(),
+		inboxSize:  DefaultInboxSize,
+		loadFactor: DefaultLoadFactor,
+		vnodes:     DefaultVirtualNodes,
+		abort:      make(chan struct{}),
+		stealWake:  make(chan struct{}, 1),
+		log:        slog.Default(),
+	}
+	initial := DefaultTuning()
+	s.tuning.cur.Store(&initial)
+	s.newPolicy = func() QueuePolicy {
+		// Shard thêm bởi Resize lấy watermark đang có hiệu lực
+		t := s.tuning.cur.Load()
+		return NewAdaptiveLIFO(t.HighWaterMark, t.LowWaterMark)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cacheCfg != nil {
+		if err := s.cacheCfg.Validate(); err != nil {
+			return nil, fmt.Errorf("result cache: %w", err)
+		}
+	}
 
-		go s.startWorker(i, s.workerChans[i], db)
+	// Tạo đủ shard trước khi đăng ký metric hay chạy worker, để lỗi ở đây
+	// không để lại goroutine hay collector nào
+	n := s.numShards
+	s.shards = make([]*shard, n)
+	s.ring = newHashRing(n, s.vnodes)
+	for i := range s.shards {
+		sh, err := s.newShard(i, n)
+		if err != nil {
+			for _, created := range s.shards[:i] {
+				if created.cache != nil {
+					created.cache.c.Close()
+				}
+			}
+			return nil, err
+		}
+		s.shards[i] = sh
+	}
+	if s.metricsReg != nil {
+		s.registerMetrics(s.metricsReg)
 	}
-	return s
+	for _, sh := range s.shards {
+		s.runShard(sh)
+	}
+	return s, nil
 }
 
-var ChangePoolJob bool = false
-var TotalMaxProcessOnWorker int = 80
-
-func (s *ComputeServer) startWorker(id int, jobChan <-chan *Job, db *sql.DB) {
-	// 1. Kho chứa riêng (Local Queue) để worker tự sắp xếp
-	var q []*Job
-	useLIFO := false // Mặc định là FIFO (Công bằng)
+func (s *ComputeServer) startWorker(sh *shard, db *sql.DB) {
+	defer s.workers.Done()
+	if sh.cache != nil {
+		defer sh.cache.c.Close()
+	}
+	id, jobChan := sh.id, sh.inbox
 
-	// Các ngưỡng để bật/tắt chế độ LIFO
-	const (
-		HighWaterMark = 80 // Khi hàng đợi > 80: Bật LIFO (Cứu hoả)
-		LowWaterMark  = 40 // Khi hàng đợi < 40: Về FIFO (Bình thường)
-	)
+	// 1. Kho chứa riêng (Local Queue) nằm trong sh.queue, khoá bằng sh.mu
+	// để worker rảnh có thể lấy trộm việc ở đuôi.
+	// Hàng đợi đa cấp: job ưu tiên cao lấy trước, thứ tự trong mỗi lớp do QueuePolicy quyết định.
+	// inbox đã bị đóng (Shutdown): xử lý nốt local queue rồi nghỉ
+	closed := false
 
 	// Vòng lặp xử lý vô tận
 	for {
+		// Snapshot tuning của vòng này; UpdateTuning thay cả snapshot
+		tuning := s.tuning.cur.Load()
+
 		// ==========================================
 		// PHA 1: HÚT VIỆC (INGESTION)
 		// ==========================================
 
 		// Nếu tay đang rỗng -> Ngủ chờ việc (Blocking)
 		// Giúp tiết kiệm CPU khi không có việc
-		if len(q) == 0 {
-			job, ok := <-jobChan
-			if !ok {
-				return // Channel đóng, worker nghỉ
+		var first *Job
+		if sh.queued.Load() == 0 {
+			if closed {
+				return // Hết việc và không còn việc mới, worker nghỉ
 			}
-			if job != nil {
-				q = append(q, job)
+			// Việc của chính shard đi trước: lấy inbox không chờ, chỉ đi lấy
+			// trộm khi cả local queue lẫn inbox đều rỗng
+			select {
+			case job, ok := <-jobChan:
+				if !ok {
+					return // Channel đóng, worker nghỉ
+				}
+				first = job
+			default:
+				// Trước khi ngủ, thử lấy việc của shard đang quá tải
+				if job := s.steal(sh, tuning); job != nil {
+					traceDequeue(job, sh, true)
+					s.run(sh, job, db, true)
+					continue
+				}
+				select {
+				case job, ok := <-jobChan:
+					if !ok {
+						return // Channel đóng, worker nghỉ
+					}
+					first = job
+				case <-s.stealWake:
+					continue // Có shard vượt ngưỡng steal, quay lại thử lấy
+				}
 			}
 		}
 
+		sh.mu.Lock()
+		sh.retune(tuning)
+		if first != nil {
+			sh.queue.Push(first)
+		}
+
 		// Nếu đã thức, tranh thủ hút sạch việc đang chờ trong inbox (Non-blocking)
 		// Mục đích: Gom việc vào để đo độ dài hàng đợi
 	DrainLoop:
-		for {
+		for !closed {
 			select {
 			case job, ok := <-jobChan:
 				if !ok {
-					return
+					closed = true
+					break DrainLoop
 				}
 				if job != nil {
-					q = append(q, job)
+					sh.queue.Push(job)
 				}
 			default:
 				// Inbox rỗng, ngừng hút
@@ -154,78 +356,175 @@ func (s *ComputeServer) startWorker(id int, jobChan <-chan *Job, db *sql.DB) {
 		}
 
 		// ==========================================
-		// PHA 2: CHIẾN LƯỢC THÍCH ỨNG (ADAPTIVE SWITCHING)
+		// PHA 2 + 3: CHIẾN LƯỢC THÍCH ỨNG & CHỌN VIỆC (POLICY + POP)
 		// ==========================================
 
-		curLen := len(q)
+		// QueuePolicy quyết định chế độ (FIFO/LIFO/CoDel...), job tiếp theo
+		// và các job cần bỏ.
+		d := sh.queue.Next(time.Now())
+		sh.queued.Store(int64(sh.queue.Len()))
+		sh.mu.Unlock()
+
+		for _, c := range d.Modes {
+			sh.setMode(c)
+			s.metrics.observeMode(c)
+			s.logMode(id, c)
+			s.publishMode(id, c)
+		}
+		for _, dropped := range d.Dropped {
+			s.reject(id, dropped, ErrDroppedByPolicy)
+			sh.load.Add(-1)
+		}
+		// Còn nhiều việc: đánh thức một worker rảnh sang giúp
+		s.signalSteal(sh, tuning)
 
-		// Cơ chế trễ (Hysteresis) để tránh bật/tắt liên tục
-		if !useLIFO && curLen >= HighWaterMark {
-			useLIFO = true // BẬT LIFO: Ưu tiên người mới, bỏ mặc người cũ
-			// fmt.Printf("Worker %d switched to LIFO mode (Load: %d)\n", id, curLen)
-		} else if useLIFO && curLen <= LowWaterMark {
-			useLIFO = false // VỀ FIFO: Quay lại công bằng
-			// fmt.Printf("Worker %d back to FIFO mode (Load: %d)\n", id, curLen)
+		if d.Job == nil {
+			continue
 		}
+		traceDequeue(d.Job, sh, false)
+		s.run(sh, d.Job, db, false)
+	}
+}
 
-		// ==========================================
-		// PHA 3: CHỌN VIỆC (POP)
-		// ==========================================
+// run xử lý một job shard sh đang giữ rồi trả lại phần tải của nó.
+// stolen: job được lấy trộm từ shard khác.
+func (s *ComputeServer) run(sh *shard, job *Job, db *sql.DB, stolen bool) {
+	if s.aborted() {
+		// Shutdown hết thời gian chờ: từ chối thay vì để client treo
+		s.reject(sh.id, job, ErrShuttingDown)
+	} else {
+		start := time.Now()
+		sh.running.Store(&InFlightJob{
+			QueryID:    job.QueryId,
+			Kind:       jobKind(job),
+			Priority:   job.Priority,
+			EnqueuedAt: job.EnqueuedAt,
+			StartedAt:  start,
+			Stolen:     stolen,
+		})
+		s.process(sh, job, db)
+		sh.running.Store(nil)
+		s.metrics.observeRun(job, start)
+	}
+	sh.load.Add(-1)
+}
 
-		var job *Job
-		if useLIFO {
-			// LIFO: Lấy việc ở CUỐI hàng (Mới nhất)
-			lastIdx := len(q) - 1
-			job = q[lastIdx]
-			q = q[:lastIdx] // Cắt đuôi
-		} else {
-			// FIFO: Lấy việc ở ĐẦU hàng (Cũ nhất)
-			job = q[0]
-			q = q[1:] // Cắt đầu
-		}
+// process chạy một job đã lấy ra khỏi hàng đợi và luôn trả lời RespChan.
+func (s *ComputeServer) process(sh *shard, job *Job, db *sql.DB) {
+	id := sh.id
+	// ==========================================
+	// PHA 4: KIỂM TRA (CHECK)
+	// ==========================================
 
-		// ==========================================
-		// PHA 4: KIỂM TRA (CHECK)
-		// ==========================================
+	// 1. Kiểm tra khách có hủy kèo chưa (Context Done)
+	select {
+	case <-job.Ctx.Done():
+		// Khách hủy rồi -> Không làm nữa, báo lại để không ai phải chờ
+		s.reject(id, job, job.Ctx.Err())
+		return
+	default:
+	}
 
-		// 1. Kiểm tra khách có hủy kèo chưa (Context Done)
-		select {
-		case <-job.Ctx.Done():
-			// Khách hủy rồi -> Bỏ qua, không làm nữa
-			continue
-		default:
-		}
+	// 2. Job thiu (Stale): chờ quá MaxQueueAge -> DROP NGAY LẬP TỨC
+	if maxAge := s.tuning.cur.Load().MaxQueueAge; maxAge > 0 && time.Since(job.EnqueuedAt) > maxAge {
+		s.reject(id, job, ErrExpiredInQueue)
+		return
+	}
 
-		// ==========================================
-		// PHA 5: THỰC THI (EXECUTION)
-		// ==========================================
+	// ==========================================
+	// PHA 5: THỰC THI (EXECUTION)
+	// ==========================================
+
+	ctx, span := tracer.Start(jobContext(job, sh), "worker.process", trace.WithAttributes(
+		attrShard.Int(id),
+		attrJobKind.String(jobKind(job)),
+	))
+	var err error
+	defer func() { endSpan(span, err) }()
+
+	// Job không cần DB (ProcessSingle): giả lập tải CPU/IO
+	if job.Work != nil {
+		var resp *pb.WorkResponse
+		resp, err = runWork(job, time.Now())
+		s.sendWork(job, resp, err)
+		s.publishResult(id, job, "work", resp, err)
+		return
+	}
 
-		// Giả lập xử lý nặng (DB Query, Calculation...)
-		// time.Sleep(10 * time.Millisecond) // Uncomment để test delay
-		records, err := ExecuteSQLQery(job.CT.GetQuerySQL(), db)
-		if err != nil {
-			s.send(job, nil, err)
-			continue
-		}
-		payloadSize := int32(0)
-		if job.CT != nil {
-			payloadSize = int32(len(job.CT.Payload))
-		}
+	payloadSize := int32(0)
+	if job.CT != nil {
+		payloadSize = int32(len(job.CT.Payload))
+	}
+
+	rq, err := s.resolveQuery(job.CT)
+	if rq.meta != nil {
+		span.SetAttributes(attrQueryName.String(rq.meta.Name))
+	}
+	if err != nil {
+		s.send(job, nil, err)
+		s.publishResult(id, job, "sql", nil, err)
+		return
+	}
 
-		// Tạo kết quả
-		resp := &pb.TestHTTP3Response{
-			Status:       "True",
-			QueryId:      job.QueryId,
-			ReceivedSize: payloadSize,
-			Records:      records,
+	// Cache-Aside: Check Cache -> Hit -> Return. Chỉ query read-only trong
+	// registry mới được cache: SQL lạ hoặc lệnh ghi luôn phải chạy thật.
+	cache := sh.cache
+	if !cacheable(rq.meta) {
+		cache = nil
+	}
+	var key string
+	if cache != nil {
+		key = cacheKey(rq.sql, rq.args...)
+		if cached, ok := s.cacheGet(cache, key); ok {
+			s.send(job, &pb.TestHTTP3Response{
+				Status:       cached.Status,
+				QueryId:      job.QueryId,
+				ReceivedSize: payloadSize,
+				Records:      cached.Records,
+				Truncated:    cached.Truncated,
+			}, nil)
+			s.publishResult(id, job, "sql", nil, nil)
+			span.AddEvent("cache.hit")
+			return
 		}
+	}
 
-		// Gửi trả kết quả
-		s.send(job, resp, nil)
+	// Giả lập xử lý nặng (DB Query, Calculation...)
+	// time.Sleep(10 * time.Millisecond) // Uncomment để test delay
+	// Job.Ctx mang deadline của request gRPC/HTTP: client bỏ đi thì query bị huỷ theo.
+	records, truncated, err := s.query(ctx, db, rq)
+	if err != nil {
+		s.log.WarnContext(ctx, "query failed", slog.Any("error", err))
+		s.send(job, nil, err)
+		s.publishResult(id, job, "sql", nil, err)
+		return
 	}
 
+	// Tạo kết quả
+	resp := &pb.TestHTTP3Response{
+		Status:       "True",
+		QueryId:      job.QueryId,
+		ReceivedSize: payloadSize,
+		Records:      records,
+		Truncated:    truncated,
+	}
+
+	// Miss -> Execute DB -> Set Cache (TTL). Chỉ lưu phần không phụ thuộc request.
+	if cache != nil {
+		s.cacheSet(cache, key, &pb.TestHTTP3Response{Status: resp.Status, Records: records, Truncated: truncated}, time.Duration(rq.meta.CacheTTL))
+	}
+
+	// Gửi trả kết quả
+	s.sendResult(job, &JobResult{Resp: resp, Queried: true})
+	s.publishResult(id, job, "sql", nil, nil)
 }
+
 func (s *ComputeServer) send(job *Job, resp *pb.TestHTTP3Response, err error) {
+	s.sendResult(job, &JobResult{Resp: resp, Err: err})
+}
+
+func (s *ComputeServer) sendResult(job *Job, r *JobResult) {
+	resp, err := r.Resp, r.Err
 	if resp == nil {
 		resp = &pb.TestHTTP3Response{Status: "Error", QueryId: job.QueryId}
 		if err == nil {
@@ -236,13 +535,48 @@ func (s *ComputeServer) send(job *Job, resp *pb.TestHTTP3Response, err error) {
 	if resp.QueryId == "" {
 		resp.QueryId = job.QueryId
 	}
-	job.RespChan <- &JobResult{Resp: resp, Err: err}
+	r.Resp, r.Err = resp, Classify(err)
+	job.RespChan <- r
 }
 
-func (s *ComputeServer) ExecuteQuery(ctx context.Context, req *pb.TestHTTP3Request) (*pb.TestHTTP3Response, error) {
-	// 1. Sharding Algorithm: Chọn Worker dựa trên QueryId
-	// Điều này đảm bảo cùng 1 QueryId luôn vào cùng 1 Worker -> Tăng Cache Hit
-	shardID := int(hashTenant(req.GetQueryId()) % uint32(s.numShards))
+// reject trả lỗi cho một job không được thực thi.
+func (s *ComputeServer) reject(shard int, job *Job, err error) {
+	s.metrics.observeReject(err)
+	s.logReject(shard, job, err)
+	if job.Work != nil {
+		s.sendWork(job, nil, err)
+		s.publishResult(shard, job, "work", nil, err)
+		return
+	}
+	s.send(job, nil, err)
+	s.publishResult(shard, job, "sql", nil, err)
+}
+
+func (s *ComputeServer) sendWork(job *Job, resp *pb.WorkResponse, err error) {
+	if resp == nil {
+		resp = &pb.WorkResponse{RequestId: job.QueryId}
+		if err == nil {
+			err = fmt.Errorf("nil response")
+		}
+	}
+	job.RespChan <- &JobResult{Work: resp, Err: Classify(err)}
+}
+
+func (s *ComputeServer) ExecuteQuery(ctx context.Context, req *pb.TestHTTP3Request) (resp *pb.TestHTTP3Response, err error) {
+	ctx, span := tracer.Start(ctx, "ComputeServer.ExecuteQuery", trace.WithAttributes(attrQueryID.String(req.GetQueryId())))
+	defer func() { endSpan(span, err) }()
+
+	// Admission: vượt limit thì từ chối ngay, không để query xếp hàng.
+	// Chỉ query thật sự chạy trên DB mới là mẫu latency cho limiter.
+	var queried bool
+	if s.limiter != nil {
+		if !s.limiter.acquire() {
+			s.metrics.observeReject(ErrLimitExceeded)
+			return nil, ErrLimitExceeded
+		}
+		start := time.Now()
+		defer func() { s.limiter.release(time.Since(start), err, queried) }()
+	}
 
 	job := &Job{
 		Ctx:      ctx,
@@ -251,42 +585,94 @@ func (s *ComputeServer) ExecuteQuery(ctx context.Context, req *pb.TestHTTP3Reque
 		RespChan: make(chan *JobResult, 1),
 	}
 
-	if len(s.workerChans[shardID]) > TotalMaxProcessOnWorker {
-		shardID = (shardID + 1) % s.numShards
+	result, err := s.dispatch(ctx, job)
+	if err != nil {
+		return nil, err
 	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	queried = result.Queried
+	// Response do worker tạo riêng cho job này: chỉ cần đặt lại QueryId
+	resp = result.Resp
+	resp.QueryId = req.GetQueryId()
+	return resp, nil
+}
 
-	// 2. Đẩy Job vào hàng đợi của Worker tương ứng (Producer)
-	select {
-	case s.workerChans[shardID] <- job:
-		// Đã gửi thành công
-	case <-ctx.Done():
-		return nil, ctx.Err() // Client hủy request
-	default:
-		// Backpressure: Nếu hàng đợi đầy, từ chối ngay lập tức
-		return nil, fmt.Errorf("Server overloaded, please retry later")
+// pickShard chọn shard theo Bounded-Load Consistent Hashing: đi trên vòng hash
+// từ vị trí của key, lấy shard đầu tiên có tải dưới ngưỡng
+// loadFactor * tải trung bình và inbox chưa vượt Tuning.MaxInboxDepth.
+// Trả về nil nếu không shard nào nhận được. Gọi khi đang giữ s.mu.
+func (s *ComputeServer) pickShard(key string) *shard {
+	var total int64
+	for _, sh := range s.shards {
+		total += sh.load.Load()
+	}
+	bound := loadBound(total, len(s.shards), s.loadFactor)
+	maxDepth := s.tuning.cur.Load().MaxInboxDepth
+
+	var picked *shard
+	s.ring.walk(key, func(id int) bool {
+		sh := s.shards[id]
+		if sh.load.Load()+1 <= bound && len(sh.inbox) <= maxDepth {
+			picked = sh
+			return true
+		}
+		return false
+	})
+	return picked
+}
+
+// dispatch routes a job to its shard and waits for the worker's result.
+func (s *ComputeServer) dispatch(ctx context.Context, job *Job) (*JobResult, error) {
+	if err := s.enqueue(ctx, job); err != nil {
+		s.metrics.observeReject(err)
+		return nil, Classify(err)
 	}
 
 	// 3. Chờ kết quả từ Worker
 	select {
 	case result := <-job.RespChan:
-		if result.Err != nil {
-			return nil, result.Err
-		}
-		originResp := result.Resp
-		return &pb.TestHTTP3Response{
-			Status:       originResp.Status,
-			QueryId:      req.GetQueryId(),
-			Records:      originResp.Records,
-			ReceivedSize: originResp.ReceivedSize,
-		}, nil
+		return result, nil
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, Classify(ctx.Err())
 	}
 }
 
-// Hàm băm đơn giản để Sharding
-func hashTenant(QueryId string) uint32 {
-	h := fnv.New32a()
-	h.Write([]byte(QueryId))
-	return h.Sum32()
+// enqueue chọn shard và đẩy job vào inbox của shard đó mà không chờ.
+func (s *ComputeServer) enqueue(ctx context.Context, job *Job) error {
+	// Read lock: Shutdown không thể đóng inbox trong lúc đang gửi
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closing {
+		return ErrShuttingDown
+	}
+
+	// 1. Sharding Algorithm: Chọn Worker dựa trên QueryId
+	// Điều này đảm bảo cùng 1 QueryId luôn vào cùng 1 Worker -> Tăng Cache Hit
+	sh := s.pickShard(job.QueryId)
+	if sh == nil {
+		// Inbox của mọi shard đều vượt ngưỡng
+		primary := s.shards[s.ring.primary(job.QueryId)]
+		s.publishOverload(primary.id, job, len(primary.inbox))
+		return ErrOverloaded
+	}
+
+	// 2. Đẩy Job vào hàng đợi của Worker tương ứng (Producer)
+	job.EnqueuedAt = time.Now()
+	sh.load.Add(1)
+	select {
+	case sh.inbox <- job:
+		// Đã gửi thành công
+		traceEnqueue(job, sh)
+		return nil
+	case <-ctx.Done():
+		sh.load.Add(-1)
+		return ctx.Err() // Client hủy request
+	default:
+		// Backpressure: Nếu hàng đợi đầy, từ chối ngay lập tức
+		sh.load.Add(-1)
+		s.publishOverload(sh.id, job, len(sh.inbox))
+		return ErrOverloaded
+	}
 }
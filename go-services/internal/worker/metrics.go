@@ -0,0 +1,217 @@
+package worker
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// MetricsNamespace là prefix của mọi metric Prometheus do worker export.
+const MetricsNamespace = "laminar"
+
+// Bucket cho queue wait và thời gian chạy: từ 100µs tới ~6.5s.
+var latencyBuckets = prometheus.ExponentialBuckets(0.0001, 2, 17)
+
+// workerMetrics là các metric cập nhật trên đường xử lý job. Nil = tắt,
+// mọi method đều an toàn khi gọi trên nil.
+type workerMetrics struct {
+	queueWait   *prometheus.HistogramVec
+	execution   *prometheus.HistogramVec
+	modeSwitch  *prometheus.CounterVec
+	rejections  *prometheus.CounterVec
+	cacheLookup *prometheus.CounterVec
+}
+
+// WithMetrics registers the compute server's Prometheus metrics on reg:
+// per-shard queue length and mode, mode switches, queue-wait and execution
+// histograms, rejections by ErrorInfo reason, result cache, work stealing,
+// limiter and tuning state, and the sql.DB pool stats.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(s *ComputeServer) {
+		s.metricsReg = reg
+	}
+}
+
+// registerMetrics tạo và đăng ký metric sau khi mọi Option đã áp dụng.
+func (s *ComputeServer) registerMetrics(reg prometheus.Registerer) {
+	m := &workerMetrics{
+		queueWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "worker",
+			Name:      "queue_wait_seconds",
+			Help:      "Time a job spent in its shard queue before a worker picked it up.",
+			Buckets:   latencyBuckets,
+		}, []string{"kind"}),
+		execution: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "worker",
+			Name:      "execution_seconds",
+			Help:      "Time a worker spent running a job, including cache lookups.",
+			Buckets:   latencyBuckets,
+		}, []string{"kind"}),
+		modeSwitch: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "worker",
+			Name:      "queue_mode_switches_total",
+			Help:      "Queue mode switches by the queue policy, by new mode and priority class.",
+		}, []string{"mode", "priority"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "worker",
+			Name:      "rejections_total",
+			Help:      "Jobs answered with an error without being executed, by ErrorInfo reason.",
+		}, []string{"reason"}),
+		cacheLookup: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "worker",
+			Name:      "cache_lookups_total",
+			Help:      "Result cache lookups by result (hit or miss).",
+		}, []string{"result"}),
+	}
+	reg.MustRegister(m.queueWait, m.execution, m.modeSwitch, m.rejections, m.cacheLookup, &stateCollector{s: s})
+	if s.db != nil {
+		reg.MustRegister(collectors.NewDBStatsCollector(s.db, MetricsNamespace))
+	}
+	s.metrics = m
+}
+
+func jobKind(job *Job) string {
+	if job.Work != nil {
+		return "work"
+	}
+	return "sql"
+}
+
+func (m *workerMetrics) observeRun(job *Job, start time.Time) {
+	if m == nil {
+		return
+	}
+	kind := jobKind(job)
+	m.queueWait.WithLabelValues(kind).Observe(start.Sub(job.EnqueuedAt).Seconds())
+	m.execution.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+}
+
+func (m *workerMetrics) observeMode(c ModeChange) {
+	if m == nil {
+		return
+	}
+	m.modeSwitch.WithLabelValues(c.Mode, strconv.Itoa(int(c.Priority))).Inc()
+}
+
+// observeReject đếm err theo reason trong ErrorInfo (sau Classify).
+func (m *workerMetrics) observeReject(err error) {
+	if m == nil {
+		return
+	}
+	reason := ErrorReason(Classify(err))
+	if reason == "" {
+		reason = ReasonInternal
+	}
+	m.rejections.WithLabelValues(reason).Inc()
+}
+
+func (m *workerMetrics) observeCache(hit bool) {
+	if m == nil {
+		return
+	}
+	if hit {
+		m.cacheLookup.WithLabelValues("hit").Inc()
+	} else {
+		m.cacheLookup.WithLabelValues("miss").Inc()
+	}
+}
+
+// stateCollector đọc trạng thái hiện tại (shard, steal, limiter, tuning) lúc
+// scrape, nên số shard đổi theo Resize mà không cần đăng ký lại.
+type stateCollector struct {
+	s *ComputeServer
+}
+
+var (
+	descShards = prometheus.NewDesc(MetricsNamespace+"_worker_shards",
+		"Shards currently accepting jobs.", nil, nil)
+	descQueueLen = prometheus.NewDesc(MetricsNamespace+"_worker_queue_length",
+		"Jobs waiting in a shard, in its inbox or local queue.", []string{"shard"}, nil)
+	descLoad = prometheus.NewDesc(MetricsNamespace+"_worker_shard_load",
+		"Jobs a shard holds, queued or running.", []string{"shard"}, nil)
+	descMode = prometheus.NewDesc(MetricsNamespace+"_worker_queue_mode",
+		"Current queue mode of a shard's priority class (1 for the active mode).", []string{"shard", "priority", "mode"}, nil)
+	descSteals = prometheus.NewDesc(MetricsNamespace+"_worker_steals_total",
+		"Jobs taken from another shard by an idle worker.", nil, nil)
+	descStealMisses = prometheus.NewDesc(MetricsNamespace+"_worker_steal_misses_total",
+		"Steal attempts whose victim dropped below the threshold.", nil, nil)
+	descLimit = prometheus.NewDesc(MetricsNamespace+"_limiter_limit",
+		"Current adaptive concurrency limit.", nil, nil)
+	descInFlight = prometheus.NewDesc(MetricsNamespace+"_limiter_in_flight",
+		"Queries admitted by the limiter and not yet finished.", nil, nil)
+	descNoLoadRTT = prometheus.NewDesc(MetricsNamespace+"_limiter_no_load_rtt_seconds",
+		"Latency baseline the limiter compares samples against.", nil, nil)
+	descCacheHitRatio = prometheus.NewDesc(MetricsNamespace+"_worker_cache_hit_ratio",
+		"Result cache hits / lookups since start.", nil, nil)
+	descTuning = prometheus.NewDesc(MetricsNamespace+"_worker_tuning",
+		"Worker tuning currently in effect.", []string{"param"}, nil)
+	descTuningUpdates = prometheus.NewDesc(MetricsNamespace+"_worker_tuning_updates_total",
+		"Tuning changes applied (result=applied) or refused as invalid (result=rejected).", []string{"result"}, nil)
+)
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		descShards, descQueueLen, descLoad, descMode, descSteals, descStealMisses,
+		descLimit, descInFlight, descNoLoadRTT, descCacheHitRatio, descTuning, descTuningUpdates,
+	} {
+		ch <- d
+	}
+}
+
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.s
+	s.mu.RLock()
+	shards := s.shards
+	s.mu.RUnlock()
+
+	ch <- prometheus.MustNewConstMetric(descShards, prometheus.GaugeValue, float64(len(shards)))
+	for _, sh := range shards {
+		id := strconv.Itoa(sh.id)
+		queued := sh.queued.Load() + int64(len(sh.inbox))
+		ch <- prometheus.MustNewConstMetric(descQueueLen, prometheus.GaugeValue, float64(queued), id)
+		ch <- prometheus.MustNewConstMetric(descLoad, prometheus.GaugeValue, float64(sh.load.Load()), id)
+		for p := range sh.modes {
+			ch <- prometheus.MustNewConstMetric(descMode, prometheus.GaugeValue, 1, id, strconv.Itoa(p), sh.mode(p))
+		}
+	}
+
+	steals := s.StealStats()
+	ch <- prometheus.MustNewConstMetric(descSteals, prometheus.CounterValue, float64(steals.Steals))
+	ch <- prometheus.MustNewConstMetric(descStealMisses, prometheus.CounterValue, float64(steals.Misses))
+
+	if s.limiter != nil {
+		ls := s.limiter.stats()
+		ch <- prometheus.MustNewConstMetric(descLimit, prometheus.GaugeValue, float64(ls.Limit))
+		ch <- prometheus.MustNewConstMetric(descInFlight, prometheus.GaugeValue, float64(ls.InFlight))
+		ch <- prometheus.MustNewConstMetric(descNoLoadRTT, prometheus.GaugeValue, ls.NoLoadRTT.Seconds())
+	}
+
+	if s.cacheCfg != nil {
+		cs := s.CacheStats()
+		ratio := 0.0
+		if lookups := cs.Hits + cs.Misses; lookups > 0 {
+			ratio = float64(cs.Hits) / float64(lookups)
+		}
+		ch <- prometheus.MustNewConstMetric(descCacheHitRatio, prometheus.GaugeValue, ratio)
+	}
+
+	ts := s.TuningStats()
+	for param, v := range map[string]float64{
+		"high_water_mark": float64(ts.HighWaterMark),
+		"low_water_mark":  float64(ts.LowWaterMark),
+		"max_inbox_depth": float64(ts.MaxInboxDepth),
+		"steal_threshold": float64(ts.StealThreshold),
+		"max_queue_age":   ts.MaxQueueAge.Seconds(),
+	} {
+		ch <- prometheus.MustNewConstMetric(descTuning, prometheus.GaugeValue, v, param)
+	}
+	ch <- prometheus.MustNewConstMetric(descTuningUpdates, prometheus.CounterValue, float64(ts.Updates), "applied")
+	ch <- prometheus.MustNewConstMetric(descTuningUpdates, prometheus.CounterValue, float64(ts.Rejected), "rejected")
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// QueuePolicy decides the order in which a shard runs its queued jobs and
+// which jobs it sheds under overload. Each shard owns one instance and always
+// calls it with the shard lock held, so implementations need no locking.
+type QueuePolicy interface {
+	Push(job *Job)
+	// Next returns the job to run now (nil when empty) together with any jobs
+	// the policy decided to drop and any mode switches to report.
+	Next(now time.Time) QueueDecision
+	// StealTail removes a job for another worker to run, nil when empty.
+	StealTail() *Job
+	Len() int
+}
+
+// QueueDecision là kết quả của một lần QueuePolicy.Next.
+type QueueDecision struct {
+	Job *Job
+	// Dropped được worker trả lời bằng ErrDroppedByPolicy.
+	Dropped []*Job
+	Modes   []ModeChange
+}
+
+// ModeChange ghi lại một lần đổi chế độ để worker publish event queue.mode.
+type ModeChange struct {
+	Priority int32
+	Mode     string
+	QueueLen int
+}
+
+// ErrDroppedByPolicy trả về cho job bị QueuePolicy loại khỏi hàng đợi.
+var ErrDroppedByPolicy = newStatusError(codes.Unavailable, ReasonDroppedByPolicy, "job dropped by queue policy", retryDropped)
+
+// WithQueuePolicy sets the queue policy of every shard. newPolicy is called
+// once per shard, including shards added by Resize. The default is
+// NewAdaptiveLIFO with the watermarks of the current Tuning. Policies that
+// implement TunablePolicy follow later UpdateTuning calls.
+func WithQueuePolicy(newPolicy func() QueuePolicy) Option {
+	return func(s *ComputeServer) {
+		if newPolicy != nil {
+			s.newPolicy = newPolicy
+		}
+	}
+}
+
+// Các ngưỡng mặc định (DefaultTuning) để bật/tắt chế độ LIFO, áp dụng riêng cho từng lớp ưu tiên.
+const (
+	HighWaterMark = 80 // Khi hàng đợi > 80: Bật LIFO (Cứu hoả)
+	LowWaterMark  = 40 // Khi hàng đợi < 40: Về FIFO (Bình thường)
+)
+
+// adaptiveLIFO chạy FIFO lúc bình thường và chuyển sang LIFO khi hàng đợi
+// của một lớp vượt high, về lại FIFO khi xuống dưới low (hysteresis).
+type adaptiveLIFO struct {
+	localQueue
+	high, low int
+}
+
+// NewAdaptiveLIFO returns the FIFO/LIFO hysteresis policy: a priority class
+// switches to LIFO once it holds high jobs and back to FIFO at low.
+func NewAdaptiveLIFO(high, low int) QueuePolicy {
+	return &adaptiveLIFO{high: high, low: low}
+}
+
+// Tune đổi ngưỡng LIFO/FIFO; lớp đang LIFO giữ nguyên chế độ tới lần Next sau.
+func (p *adaptiveLIFO) Tune(t Tuning) {
+	p.high, p.low = t.HighWaterMark, t.LowWaterMark
+}
+
+func (p *adaptiveLIFO) Next(time.Time) QueueDecision {
+	var d QueueDecision
+	for i := range p.classes {
+		c := &p.classes[i]
+		curLen := len(c.jobs)
+		// Cơ chế trễ (Hysteresis) để tránh bật/tắt liên tục
+		if !c.lifo && curLen >= p.high {
+			c.lifo = true // BẬT LIFO: Ưu tiên người mới, bỏ mặc người cũ
+			d.Modes = append(d.Modes, ModeChange{Priority: int32(i), Mode: "LIFO", QueueLen: curLen})
+		} else if c.lifo && curLen <= p.low {
+			c.lifo = false // VỀ FIFO: Quay lại công bằng
+			d.Modes = append(d.Modes, ModeChange{Priority: int32(i), Mode: "FIFO", QueueLen: curLen})
+		}
+	}
+	d.Job = p.pop()
+	return d
+}
@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pushJobs đẩy vào p các job có QueryId theo thứ tự ids, ưu tiên là phần sau
+// dấu ':' (vd "h1:1"), mặc định PriorityNormal.
+func pushJobs(p QueuePolicy, ids ...string) {
+	for _, id := range ids {
+		var prio int32
+		if name, pr, ok := strings.Cut(id, ":"); ok {
+			fmt.Sscan(pr, &prio)
+			id = name
+		}
+		p.Push(&Job{QueryId: id, Priority: prio, EnqueuedAt: t0})
+	}
+}
+
+// drain gọi Next tới khi hàng đợi rỗng và trả về QueryId theo thứ tự chạy.
+func drain(p QueuePolicy) []string {
+	var order []string
+	for p.Len() > 0 {
+		order = append(order, p.Next(t0).Job.QueryId)
+	}
+	return order
+}
+
+func TestPriorityOrdering(t *testing.T) {
+	// 10 job cao liên tiếp với 1 job thường đang chờ: job thường chạy sau StarvationLimit job cao
+	var starving []string
+	for i := 1; i <= 10; i++ {
+		starving = append(starving, fmt.Sprintf("h%d:1", i))
+	}
+
+	tests := []struct {
+		name string
+		push []string
+		want string
+	}{
+		{"high before normal", []string{"n1", "h1:1", "n2", "h2:1"}, "h1 h2 n1 n2"},
+		{"FIFO within a class", []string{"n1", "n2", "n3"}, "n1 n2 n3"},
+		{"priority above high is high", []string{"n1", "h1:7"}, "h1 n1"},
+		{"negative priority is normal", []string{"x:-1", "h1:1"}, "h1 x"},
+		{"normal served after starvation limit", append([]string{"n1"}, starving...), "h1 h2 h3 h4 h5 h6 h7 h8 n1 h9 h10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewAdaptiveLIFO(100, 50)
+			pushJobs(p, tt.push...)
+			if got := strings.Join(drain(p), " "); got != tt.want {
+				t.Errorf("run order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStealTailPrefersNewestHighJob(t *testing.T) {
+	p := NewAdaptiveLIFO(100, 50)
+	pushJobs(p, "n1", "h1:1", "n2", "h2:1")
+	var got []string
+	for job := p.StealTail(); job != nil; job = p.StealTail() {
+		got = append(got, job.QueryId)
+	}
+	if want := "h2 h1 n2 n1"; strings.Join(got, " ") != want {
+		t.Fatalf("steal order = %v, want %s", got, want)
+	}
+}
+
+func TestAdaptiveLIFOWatermarks(t *testing.T) {
+	type step struct {
+		push  []string
+		want  string // QueryId của job Next trả về
+		modes string // các lần đổi chế độ "priority:mode@len", cách nhau bởi dấu cách
+	}
+	tests := []struct {
+		name      string
+		high, low int
+		steps     []step
+	}{
+		{"below high stays FIFO", 4, 2, []step{
+			{push: []string{"1", "2", "3"}, want: "1"},
+			{want: "2"},
+		}},
+		{"reaching high switches to LIFO", 4, 2, []step{
+			{push: []string{"1", "2", "3", "4"}, want: "4", modes: "0:LIFO@4"},
+			{push: []string{"5"}, want: "5"},
+			{want: "3"},
+		}},
+		{"hysteresis holds LIFO above low", 4, 2, []step{
+			{push: []string{"1", "2", "3", "4", "5"}, want: "5", modes: "0:LIFO@5"},
+			{want: "4"}, // còn 4 > low
+			{want: "3"}, // còn 3 > low
+			{want: "1", modes: "0:FIFO@2"},
+			{want: "2"},
+		}},
+		{"classes switch independently", 3, 1, []step{
+			{push: []string{"n1", "n2", "n3", "h1:1"}, want: "h1", modes: "0:LIFO@3"},
+			{push: []string{"h2:1", "h3:1", "h4:1"}, want: "h4", modes: "1:LIFO@3"},
+			{want: "h3"},
+			{want: "h2", modes: "1:FIFO@1"},
+			{want: "n3"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewAdaptiveLIFO(tt.high, tt.low)
+			for i, s := range tt.steps {
+				pushJobs(p, s.push...)
+				d := p.Next(t0)
+				if d.Job == nil || d.Job.QueryId != s.want {
+					t.Fatalf("step %d: Next returned %v, want %s", i, d.Job, s.want)
+				}
+				var modes []string
+				for _, c := range d.Modes {
+					modes = append(modes, fmt.Sprintf("%d:%s@%d", c.Priority, c.Mode, c.QueueLen))
+				}
+				if got := strings.Join(modes, " "); got != s.modes {
+					t.Fatalf("step %d: mode changes %q, want %q", i, got, s.modes)
+				}
+			}
+		})
+	}
+}
+
+func TestAdaptiveLIFOTune(t *testing.T) {
+	p := NewAdaptiveLIFO(100, 50)
+	pushJobs(p, "1", "2", "3", "4")
+	if d := p.Next(t0); d.Job.QueryId != "1" || len(d.Modes) != 0 {
+		t.Fatalf("Next = %s %+v before Tune, want 1 in FIFO", d.Job.QueryId, d.Modes)
+	}
+	// Hạ watermark lúc runtime: có hiệu lực từ lần Next tiếp theo
+	p.(TunablePolicy).Tune(Tuning{HighWaterMark: 3, LowWaterMark: 1})
+	d := p.Next(t0.Add(time.Millisecond))
+	if d.Job.QueryId != "4" || len(d.Modes) != 1 || d.Modes[0].Mode != "LIFO" {
+		t.Fatalf("Next = %s %+v after Tune, want 4 after switching to LIFO", d.Job.QueryId, d.Modes)
+	}
+}
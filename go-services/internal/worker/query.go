@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+
+	"github/shieldx-bot/laminar/internal/registry"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// WithQueryRegistry resolves query_name through reg and applies each query's
+// read-only, max-rows and cache TTL settings. Build reg with registry.Load or
+// registry.New, which report duplicate or invalid queries as errors. query_template and QuerySQL
+// only run when they match a statement in reg, unless WithAdHocSQL is set.
+func WithQueryRegistry(reg *registry.Registry) Option {
+	return func(s *ComputeServer) {
+		s.registry = reg
+	}
+}
+
+// WithAdHocSQL lets clients run query_template and QuerySQL statements that
+// are not in the query registry. Off by default: it gives every client the
+// database privileges of the server, so only enable it for benchmarks or
+// trusted callers.
+func WithAdHocSQL(allow bool) Option {
+	return func(s *ComputeServer) {
+		s.allowAdHoc = allow
+	}
+}
+
+// resolvedQuery là câu SQL cuối cùng worker sẽ chạy.
+type resolvedQuery struct {
+	sql  string
+	args []any
+	meta *registry.Query // nil nếu SQL không có trong registry
+}
+
+// resolveQuery trả về câu SQL và tham số cần chạy cho request:
+// query_name > query_template + args > QuerySQL (raw, deprecated). Template
+// và QuerySQL phải khớp một câu trong registry trừ khi bật WithAdHocSQL.
+func (s *ComputeServer) resolveQuery(req *pb.TestHTTP3Request) (resolvedQuery, error) {
+	var rq resolvedQuery
+
+	if name := req.GetQueryName(); name != "" {
+		var q *registry.Query
+		if s.registry != nil {
+			q, _ = s.registry.Lookup(name)
+		}
+		if q == nil {
+			return rq, newStatusError(codes.NotFound, ReasonInvalidQuery, fmt.Sprintf("unknown query %q", name), 0, "query_name", name)
+		}
+		rq.sql, rq.meta = q.SQL, q
+	} else {
+		rq.sql = req.GetQueryTemplate()
+		if rq.sql == "" {
+			if len(req.GetArgs()) > 0 {
+				return rq, newStatusError(codes.InvalidArgument, ReasonInvalidQuery, "args require query_name or query_template", 0)
+			}
+			rq.sql = req.GetQuerySQL()
+		}
+		if s.registry != nil {
+			rq.meta, _ = s.registry.Match(rq.sql)
+		}
+		// Mặc định chỉ chạy SQL đã đăng ký; SQL tuỳ ý cần WithAdHocSQL
+		if rq.meta == nil && !s.allowAdHoc {
+			return rq, newStatusError(codes.PermissionDenied, ReasonQueryRejected, "query is not in the registry and ad-hoc SQL is disabled", 0)
+		}
+	}
+
+	args, err := ArgValues(req.GetArgs())
+	if err != nil {
+		return rq, err
+	}
+	if n := countPlaceholders(rq.sql); n != len(args) {
+		return rq, newStatusError(codes.InvalidArgument, ReasonInvalidQuery, fmt.Sprintf("query expects %d args, got %d", n, len(args)), 0)
+	}
+	rq.args = args
+	return rq, nil
+}
+
+// ArgValues converts typed QueryArgs into values for database/sql.
+func ArgValues(args []*pb.QueryArg) ([]any, error) {
+	out := make([]any, len(args))
+	for i, a := range args {
+		switch v := a.GetValue().(type) {
+		case *pb.QueryArg_NullValue:
+			out[i] = nil
+		case *pb.QueryArg_StringValue:
+			out[i] = v.StringValue
+		case *pb.QueryArg_IntValue:
+			out[i] = v.IntValue
+		case *pb.QueryArg_DoubleValue:
+			out[i] = v.DoubleValue
+		case *pb.QueryArg_BoolValue:
+			out[i] = v.BoolValue
+		case *pb.QueryArg_BytesValue:
+			out[i] = v.BytesValue
+		case *pb.QueryArg_TimestampValue:
+			if err := v.TimestampValue.CheckValid(); err != nil {
+				return nil, newStatusError(codes.InvalidArgument, ReasonInvalidQuery, fmt.Sprintf("arg $%d: %v", i+1, err), 0)
+			}
+			out[i] = v.TimestampValue.AsTime()
+		default:
+			return nil, newStatusError(codes.InvalidArgument, ReasonInvalidQuery, fmt.Sprintf("arg $%d has no value", i+1), 0)
+		}
+	}
+	return out, nil
+}
+
+// countPlaceholders trả về N lớn nhất của các placeholder $N trong query,
+// bỏ qua nội dung trong dấu nháy đơn/kép, comment -- và /* */ (lồng nhau như
+// Postgres) và chuỗi dollar-quoted $$...$$ / $tag$...$tag$.
+func countPlaceholders(query string) int {
+	maxN := 0
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'' || c == '"':
+			// '' trong chuỗi là hai chuỗi liền nhau, kết quả như nhau
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return maxN
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return maxN
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			depth := 1
+			for i += 2; i < len(query) && depth > 0; i++ {
+				switch {
+				case strings.HasPrefix(query[i:], "/*"):
+					depth++
+					i++
+				case strings.HasPrefix(query[i:], "*/"):
+					depth--
+					i++
+				}
+			}
+			i--
+		case c == '$':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				if n, err := strconv.Atoi(query[i+1 : j]); err == nil && n > maxN {
+					maxN = n
+				}
+				i = j - 1
+				continue
+			}
+			// $tag$: tag rỗng hoặc là identifier không bắt đầu bằng chữ số.
+			// '$' ngay sau chữ cái là một phần của identifier (vd foo$bar).
+			if i > 0 && isTagByte(query[i-1]) {
+				continue
+			}
+			for j < len(query) && isTagByte(query[j]) {
+				j++
+			}
+			if j < len(query) && query[j] == '$' {
+				delim := query[i : j+1]
+				end := strings.Index(query[j+1:], delim)
+				if end < 0 {
+					return maxN
+				}
+				i = j + end + len(delim)
+			}
+		}
+	}
+	return maxN
+}
+
+func isTagByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,114 @@
+package worker
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github/shieldx-bot/laminar/internal/registry"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+func intArgs(vals ...int64) []*pb.QueryArg {
+	args := make([]*pb.QueryArg, len(vals))
+	for i, v := range vals {
+		args[i] = &pb.QueryArg{Value: &pb.QueryArg_IntValue{IntValue: v}}
+	}
+	return args
+}
+
+func TestResolveQuery(t *testing.T) {
+	byID := &registry.Query{Name: "by_id", SQL: "SELECT * FROM users WHERE id = $1", ReadOnly: true}
+	all := &registry.Query{Name: "all", SQL: "SELECT * FROM users"}
+	reg := mustRegistry(t, byID, all)
+
+	tests := []struct {
+		name       string
+		registry   *registry.Registry
+		allowAdHoc bool
+		req        *pb.TestHTTP3Request
+		wantSQL    string
+		wantMeta   *registry.Query
+		wantCode   codes.Code
+	}{
+		{"name", reg, false, &pb.TestHTTP3Request{QueryName: "by_id", Args: intArgs(7)}, byID.SQL, byID, codes.OK},
+		{"name wins over template and raw SQL", reg, true, &pb.TestHTTP3Request{
+			QueryName: "by_id", QueryTemplate: "SELECT 2", QuerySQL: "SELECT 3", Args: intArgs(7),
+		}, byID.SQL, byID, codes.OK},
+		{"template wins over raw SQL", reg, false, &pb.TestHTTP3Request{
+			QueryTemplate: "SELECT  * FROM users WHERE id = $1;", QuerySQL: "SELECT 3", Args: intArgs(7),
+		}, "SELECT  * FROM users WHERE id = $1;", byID, codes.OK},
+		{"registered raw SQL", reg, false, &pb.TestHTTP3Request{QuerySQL: "SELECT * FROM users"}, all.SQL, all, codes.OK},
+		{"unknown name", reg, true, &pb.TestHTTP3Request{QueryName: "missing"}, "", nil, codes.NotFound},
+		{"name without registry", nil, true, &pb.TestHTTP3Request{QueryName: "by_id"}, "", nil, codes.NotFound},
+
+		// Cổng ad-hoc: SQL ngoài registry chỉ chạy khi bật WithAdHocSQL
+		{"ad-hoc template denied", reg, false, &pb.TestHTTP3Request{QueryTemplate: "DELETE FROM users WHERE id = $1", Args: intArgs(1)}, "", nil, codes.PermissionDenied},
+		{"ad-hoc raw SQL denied", reg, false, &pb.TestHTTP3Request{QuerySQL: "SELECT 1"}, "", nil, codes.PermissionDenied},
+		{"ad-hoc denied without registry", nil, false, &pb.TestHTTP3Request{QuerySQL: "SELECT 1"}, "", nil, codes.PermissionDenied},
+		{"ad-hoc allowed", reg, true, &pb.TestHTTP3Request{QueryTemplate: "SELECT $1::int", Args: intArgs(1)}, "SELECT $1::int", nil, codes.OK},
+		{"ad-hoc allowed without registry", nil, true, &pb.TestHTTP3Request{QuerySQL: "SELECT 1"}, "SELECT 1", nil, codes.OK},
+
+		// Số tham số phải khớp placeholder lớn nhất
+		{"too few args", reg, false, &pb.TestHTTP3Request{QueryName: "by_id"}, "", nil, codes.InvalidArgument},
+		{"too many args", reg, false, &pb.TestHTTP3Request{QueryName: "by_id", Args: intArgs(1, 2)}, "", nil, codes.InvalidArgument},
+		{"args on raw SQL", reg, true, &pb.TestHTTP3Request{QuerySQL: "SELECT $1", Args: intArgs(1)}, "", nil, codes.InvalidArgument},
+		{"arg without value", reg, false, &pb.TestHTTP3Request{QueryName: "by_id", Args: []*pb.QueryArg{{}}}, "", nil, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &ComputeServer{registry: tt.registry, allowAdHoc: tt.allowAdHoc}
+			rq, err := s.resolveQuery(tt.req)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("resolveQuery error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				// Lỗi từ chối trước khi tới DB cũng mang ErrorInfo như lỗi của Classify
+				want := ReasonInvalidQuery
+				if tt.wantCode == codes.PermissionDenied {
+					want = ReasonQueryRejected
+				}
+				if got := ErrorReason(err); got != want {
+					t.Errorf("reason = %q, want %q", got, want)
+				}
+				return
+			}
+			if rq.sql != tt.wantSQL || rq.meta != tt.wantMeta {
+				t.Errorf("resolveQuery = (%q, %v), want (%q, %v)", rq.sql, rq.meta, tt.wantSQL, tt.wantMeta)
+			}
+			if len(rq.args) != len(tt.req.GetArgs()) {
+				t.Errorf("got %d args, want %d", len(rq.args), len(tt.req.GetArgs()))
+			}
+		})
+	}
+}
+
+func TestCountPlaceholders(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"SELECT 1", 0},
+		{"SELECT * FROM t WHERE a = $1 AND b = $2", 2},
+		{"SELECT $3, $1", 3},
+		{"SELECT $10", 10},
+		{"SELECT '$5', \"$6\" FROM t WHERE a = $1", 1},
+		{"SELECT 'it''s $4' WHERE a = $1", 1},
+		{"SELECT $1 -- note $9\nFROM t WHERE b = $2", 2},
+		{"SELECT $1 -- trailing $9", 1},
+		{"SELECT /* $9 */ $1", 1},
+		{"SELECT /* outer /* $8 */ $9 */ $2", 2},
+		{"SELECT /* unterminated $9", 0},
+		{"SELECT $$ $9 $$, $1", 1},
+		{"SELECT $fn$ has $$ and $9 $fn$ WHERE a = $2", 2},
+		{"SELECT $$ unterminated $9", 0},
+		{"SELECT foo$bar, $1 FROM t", 1},
+		{"SELECT 10$ FROM t WHERE a = $1", 1},
+	}
+	for _, tt := range tests {
+		if got := countPlaceholders(tt.query); got != tt.want {
+			t.Errorf("countPlaceholders(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package worker
+
+import "time"
+
+const (
+	// StarvationLimit: sau chừng này lần liên tiếp lấy job ưu tiên cao trong
+	// khi job thường vẫn đang chờ, worker phục vụ một job thường.
+	StarvationLimit = 8
+
+	numPriorities = 2
+)
+
+// classQueue là hàng đợi của một lớp ưu tiên, có chế độ FIFO/LIFO riêng.
+type classQueue struct {
+	jobs []*Job
+	lifo bool
+}
+
+func (c *classQueue) pop() *Job {
+	var job *Job
+	if c.lifo {
+		// LIFO: Lấy việc ở CUỐI hàng (Mới nhất)
+		lastIdx := len(c.jobs) - 1
+		job = c.jobs[lastIdx]
+		c.jobs[lastIdx] = nil
+		c.jobs = c.jobs[:lastIdx] // Cắt đuôi
+	} else {
+		// FIFO: Lấy việc ở ĐẦU hàng (Cũ nhất)
+		job = c.jobs[0]
+		c.jobs[0] = nil
+		c.jobs = c.jobs[1:] // Cắt đầu
+	}
+	return job
+}
+
+// localQueue là hàng đợi đa cấp dùng chung cho mọi QueuePolicy: lớp ưu tiên
+// cao luôn được lấy trước, có chống đói (starvation) cho lớp thường.
+type localQueue struct {
+	classes    [numPriorities]classQueue
+	highStreak int // số lần liên tiếp đã ưu tiên lớp cao khi lớp thường đang chờ
+}
+
+func (lq *localQueue) Push(job *Job) {
+	c := &lq.classes[normalizePriority(job.Priority)]
+	c.jobs = append(c.jobs, job)
+}
+
+func (lq *localQueue) Len() int {
+	n := 0
+	for i := range lq.classes {
+		n += len(lq.classes[i].jobs)
+	}
+	return n
+}
+
+// Oldest trả về EnqueuedAt của job chờ lâu nhất. Job vào cuối slice nên job
+// cũ nhất của mỗi lớp luôn ở đầu, kể cả khi lớp đang LIFO.
+func (lq *localQueue) Oldest() (time.Time, bool) {
+	var oldest time.Time
+	found := false
+	for i := range lq.classes {
+		c := &lq.classes[i]
+		if len(c.jobs) == 0 {
+			continue
+		}
+		if t := c.jobs[0].EnqueuedAt; !found || t.Before(oldest) {
+			oldest, found = t, true
+		}
+	}
+	return oldest, found
+}
+
+// pop lấy job tiếp theo, nil nếu hàng đợi rỗng.
+func (lq *localQueue) pop() *Job {
+	high := &lq.classes[PriorityHigh]
+	normal := &lq.classes[PriorityNormal]
+
+	switch {
+	case len(high.jobs) > 0 && len(normal.jobs) > 0:
+		if lq.highStreak >= StarvationLimit {
+			lq.highStreak = 0
+			return normal.pop()
+		}
+		lq.highStreak++
+		return high.pop()
+	case len(high.jobs) > 0:
+		lq.highStreak = 0
+		return high.pop()
+	case len(normal.jobs) > 0:
+		lq.highStreak = 0
+		return normal.pop()
+	}
+	return nil
+}
+
+// StealTail lấy job MỚI NHẤT để chuyển sang worker khác, ưu tiên lớp cao.
+// Lấy ở đuôi để không tranh với chủ shard khi nó đang FIFO (lấy ở đầu).
+func (lq *localQueue) StealTail() *Job {
+	for i := numPriorities - 1; i >= 0; i-- {
+		c := &lq.classes[i]
+		if n := len(c.jobs); n > 0 {
+			job := c.jobs[n-1]
+			c.jobs[n-1] = nil
+			c.jobs = c.jobs[:n-1]
+			return job
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	// DefaultVirtualNodes là số điểm mỗi shard chiếm trên vòng hash.
+	// Càng nhiều điểm, key chia càng đều giữa các shard.
+	DefaultVirtualNodes = 128
+
+	// DefaultLoadFactor: một shard chỉ nhận thêm job khi tải của nó
+	// chưa vượt 1.25 lần tải trung bình (Bounded-Load Consistent Hashing).
+	DefaultLoadFactor = 1.25
+
+	// minLoadBound: shard đang giữ ít hơn chừng này job không bao giờ bị coi là
+	// quá tải, để lúc tải thấp key nóng vẫn ở nguyên shard của nó (giữ cache).
+	minLoadBound = 8
+)
+
+// hashRing là vòng Consistent Hashing với virtual node. Thêm/bớt shard chỉ
+// làm dịch chuyển các key nằm cạnh điểm của shard đó.
+type hashRing struct {
+	points []uint32 // đã sắp xếp tăng dần
+	owners []int    // owners[i] là shard sở hữu points[i]
+	shards int
+}
+
+func newHashRing(shards, vnodes int) *hashRing {
+	if vnodes <= 0 {
+		vnodes = DefaultVirtualNodes
+	}
+	r := &hashRing{
+		points: make([]uint32, 0, shards*vnodes),
+		owners: make([]int, 0, shards*vnodes),
+		shards: shards,
+	}
+
+	type point struct {
+		hash  uint32
+		owner int
+	}
+	pts := make([]point, 0, shards*vnodes)
+	for id := 0; id < shards; id++ {
+		for v := 0; v < vnodes; v++ {
+			pts = append(pts, point{hash: hashTenant("shard-" + strconv.Itoa(id) + "#" + strconv.Itoa(v)), owner: id})
+		}
+	}
+	sort.Slice(pts, func(i, j int) bool {
+		if pts[i].hash == pts[j].hash {
+			return pts[i].owner < pts[j].owner
+		}
+		return pts[i].hash < pts[j].hash
+	})
+	for _, p := range pts {
+		r.points = append(r.points, p.hash)
+		r.owners = append(r.owners, p.owner)
+	}
+	return r
+}
+
+// walk gọi visit cho từng shard riêng biệt theo chiều kim đồng hồ, bắt đầu từ
+// vị trí của key (primary trước, rồi tới các backup), dừng khi visit trả về true.
+func (r *hashRing) walk(key string, visit func(shard int) bool) {
+	if len(r.points) == 0 {
+		return
+	}
+	h := hashTenant(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+
+	seen := make([]bool, r.shards)
+	remaining := r.shards
+	for i := 0; i < len(r.points) && remaining > 0; i++ {
+		owner := r.owners[(start+i)%len(r.points)]
+		if seen[owner] {
+			continue
+		}
+		seen[owner] = true
+		remaining--
+		if visit(owner) {
+			return
+		}
+	}
+}
+
+// primary trả về shard sở hữu key khi bỏ qua tải.
+func (r *hashRing) primary(key string) int {
+	shard := -1
+	r.walk(key, func(id int) bool {
+		shard = id
+		return true
+	})
+	return shard
+}
+
+// loadBound là tải tối đa một shard được phép có sau khi nhận thêm 1 job:
+// ceil(loadFactor * (totalLoad + 1) / shards), không nhỏ hơn minLoadBound.
+func loadBound(totalLoad int64, shards int, loadFactor float64) int64 {
+	if loadFactor < 1 {
+		loadFactor = 1
+	}
+	bound := int64(math.Ceil(loadFactor * float64(totalLoad+1) / float64(shards)))
+	if bound < minLoadBound {
+		bound = minLoadBound
+	}
+	return bound
+}
+
+// Hàm băm đơn giản để Sharding. FNV-1a rồi trộn thêm (finalizer của
+// murmur3) để các QueryId gần giống nhau không dồn về một đoạn của vòng.
+func hashTenant(QueryId string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(QueryId))
+	x := h.Sum32()
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
@@ -0,0 +1,172 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func testKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "query-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func TestHashRingStableWhenShardAdded(t *testing.T) {
+	before := newHashRing(8, DefaultVirtualNodes)
+	after := newHashRing(9, DefaultVirtualNodes)
+
+	keys := testKeys(10000)
+	moved := 0
+	for _, k := range keys {
+		from, to := before.primary(k), after.primary(k)
+		if from == to {
+			continue
+		}
+		moved++
+		if to != 8 {
+			t.Fatalf("key %q moved from shard %d to existing shard %d, want only moves to the new shard 8", k, from, to)
+		}
+	}
+	// Shard mới nhận khoảng 1/9 số key; cho phép lệch rộng vì virtual node
+	if lo, hi := len(keys)/18, len(keys)*2/9; moved < lo || moved > hi {
+		t.Errorf("moved %d of %d keys, want between %d and %d", moved, len(keys), lo, hi)
+	}
+}
+
+func TestHashRingStableWhenShardRemoved(t *testing.T) {
+	before := newHashRing(8, DefaultVirtualNodes)
+	after := newHashRing(7, DefaultVirtualNodes)
+
+	for _, k := range testKeys(10000) {
+		from, to := before.primary(k), after.primary(k)
+		if from != 7 && from != to {
+			t.Fatalf("key %q on surviving shard %d moved to %d", k, from, to)
+		}
+		if to == 7 {
+			t.Fatalf("key %q still maps to removed shard 7", k)
+		}
+	}
+}
+
+func TestHashRingWalkVisitsEveryShardOnce(t *testing.T) {
+	r := newHashRing(5, 16)
+	var order []int
+	r.walk("some-key", func(id int) bool {
+		order = append(order, id)
+		return false
+	})
+	if len(order) != 5 {
+		t.Fatalf("walk visited %v, want all 5 shards", order)
+	}
+	seen := map[int]bool{}
+	for _, id := range order {
+		if seen[id] {
+			t.Fatalf("walk visited shard %d twice: %v", id, order)
+		}
+		seen[id] = true
+	}
+	if order[0] != r.primary("some-key") {
+		t.Errorf("walk starts at %d, primary is %d", order[0], r.primary("some-key"))
+	}
+}
+
+func TestLoadBound(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int64
+		shards     int
+		loadFactor float64
+		want       int64
+	}{
+		{"idle uses minimum", 0, 4, 1.25, minLoadBound},
+		{"low load uses minimum", 20, 4, 1.25, minLoadBound},
+		{"factor above mean", 99, 4, 1.25, 32}, // ceil(1.25*100/4) = 32
+		{"rounds up", 100, 4, 1.25, 32},        // ceil(1.25*101/4) = 31.5625 -> 32
+		{"factor below 1 treated as 1", 99, 4, 0.5, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loadBound(tt.total, tt.shards, tt.loadFactor); got != tt.want {
+				t.Errorf("loadBound(%d, %d, %v) = %d, want %d", tt.total, tt.shards, tt.loadFactor, got, tt.want)
+			}
+		})
+	}
+}
+
+// newRingServer tạo ComputeServer chỉ đủ cho pickShard/enqueue, không chạy worker.
+func newRingServer(shards int) *ComputeServer {
+	s := &ComputeServer{
+		ring:       newHashRing(shards, DefaultVirtualNodes),
+		loadFactor: DefaultLoadFactor,
+	}
+	t := DefaultTuning()
+	s.tuning.cur.Store(&t)
+	for i := 0; i < shards; i++ {
+		s.shards = append(s.shards, &shard{id: i, inbox: make(chan *Job, DefaultInboxSize)})
+	}
+	return s
+}
+
+func TestPickShardPrefersPrimary(t *testing.T) {
+	s := newRingServer(4)
+	for _, k := range testKeys(100) {
+		if got, want := s.pickShard(k).id, s.ring.primary(k); got != want {
+			t.Fatalf("pickShard(%q) = %d, want primary %d", k, got, want)
+		}
+	}
+}
+
+func TestPickShardHonorsLoadBound(t *testing.T) {
+	s := newRingServer(4)
+	const key = "hot-key"
+	primary := s.ring.primary(key)
+	var backup int
+	s.ring.walk(key, func(id int) bool {
+		backup = id
+		return id != primary
+	})
+
+	// Tổng tải 100 -> bound = ceil(1.25*101/4) = 32; primary đã ở mức bound
+	s.shards[primary].load.Store(32)
+	s.shards[backup].load.Store(20)
+	for _, sh := range s.shards {
+		if sh.id != primary && sh.id != backup {
+			sh.load.Store(24)
+		}
+	}
+	if got := s.pickShard(key); got == nil || got.id != backup {
+		t.Fatalf("pickShard = %v, want backup shard %d when primary %d is at the bound", got, backup, primary)
+	}
+
+	// Primary xuống dưới bound thì key quay về shard của nó
+	s.shards[primary].load.Store(30)
+	if got := s.pickShard(key); got == nil || got.id != primary {
+		t.Fatalf("pickShard = %v, want primary shard %d once it is under the bound", got, primary)
+	}
+}
+
+func TestPickShardAllSaturated(t *testing.T) {
+	s := newRingServer(4)
+	for _, sh := range s.shards {
+		sh.load.Store(1000)
+	}
+	// Bound luôn >= tải trung bình nên luôn có shard còn chỗ theo tải; shard
+	// chỉ hết chỗ thật khi inbox vượt MaxInboxDepth. Khi đó job bị từ chối.
+	for _, sh := range s.shards {
+		for len(sh.inbox) <= s.Tuning().MaxInboxDepth {
+			sh.inbox <- &Job{}
+		}
+	}
+	if got := s.pickShard("any"); got != nil {
+		t.Fatalf("pickShard = shard %d, want nil when every inbox is over MaxInboxDepth", got.id)
+	}
+
+	job := &Job{Ctx: context.Background(), QueryId: "any"}
+	if err := s.enqueue(context.Background(), job); !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("enqueue = %v, want ErrOverloaded", err)
+	}
+}
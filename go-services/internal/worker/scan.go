@@ -0,0 +1,206 @@
+package worker
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ScanRows đọc mọi dòng của rows thành structpb.Struct (tên cột -> giá trị),
+// không cần biết schema trước. Dừng sau limit dòng (0 = không giới hạn);
+// dùng ScanRowsLimit để biết kết quả có bị cắt không. rows luôn được đóng
+// trước khi trả về, nên có thể Commit transaction ngay sau đó.
+//
+// Cách chuyển kiểu Postgres -> structpb.Value:
+//
+//	NULL                           -> null
+//	int2, int4, int8               -> number; nếu |v| > 2^53 thì string (tránh mất chính xác)
+//	float4, float8                 -> number; NaN/Infinity -> string
+//	numeric                        -> string (giữ nguyên độ chính xác, vd "12.3400")
+//	bool                           -> bool
+//	text, varchar, char, uuid, ... -> string
+//	timestamp, timestamptz         -> string RFC 3339 (vd "2024-05-01T10:00:00.123Z")
+//	date                           -> string "2006-01-02"
+//	time, timetz                   -> string "15:04:05.999999" (timetz kèm offset)
+//	bytea                          -> string base64 (chuẩn, có padding)
+//	json, jsonb                    -> giá trị JSON đã parse (object, array, ...)
+//	mảng 1 chiều (int[], text[]..) -> list, phần tử chuyển theo kiểu phần tử ở trên
+//	mảng nhiều chiều, kiểu khác    -> string (dạng text của Postgres)
+//
+// Hai cột trùng tên thì cột sau ghi đè cột trước; dùng alias trong SQL để tránh.
+func ScanRows(rows *sql.Rows, limit int) ([]*structpb.Struct, error) {
+	records, _, err := ScanRowsLimit(rows, limit)
+	return records, err
+}
+
+// ScanRowsLimit is ScanRows that also reports whether rows had more than
+// limit rows, i.e. whether the result was truncated.
+func ScanRowsLimit(rows *sql.Rows, limit int) (records []*structpb.Struct, truncated bool, err error) {
+	defer rows.Close()
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, false, err
+	}
+
+	raw := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+
+	for rows.Next() {
+		if limit > 0 && len(records) >= limit {
+			// Còn dòng sau dòng thứ limit: kết quả bị cắt
+			truncated = true
+			break
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, false, err
+		}
+
+		fields := make(map[string]*structpb.Value, len(cols))
+		for i, col := range cols {
+			v, err := columnValue(col.DatabaseTypeName(), raw[i])
+			if err != nil {
+				return nil, false, fmt.Errorf("column %q: %w", col.Name(), err)
+			}
+			fields[col.Name()] = v
+		}
+		records = append(records, &structpb.Struct{Fields: fields})
+	}
+
+	// Đóng ngay (kể cả khi dừng sớm vì limit) để connection/transaction không
+	// còn statement dở dang, rồi mới đọc lỗi của lần đọc cuối.
+	if err := rows.Close(); err != nil {
+		return nil, false, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	return records, truncated, nil
+}
+
+// maxSafeInteger là số nguyên lớn nhất float64 (và JSON number) biểu diễn chính xác.
+const maxSafeInteger = 1 << 53
+
+// columnValue chuyển giá trị driver trả về cho một cột kiểu typeName.
+func columnValue(typeName string, v any) (*structpb.Value, error) {
+	switch x := v.(type) {
+	case nil:
+		return structpb.NewNullValue(), nil
+	case int64:
+		return intValue(x), nil
+	case float64:
+		return floatValue(x), nil
+	case bool:
+		return structpb.NewBoolValue(x), nil
+	case string:
+		return structpb.NewStringValue(x), nil
+	case time.Time:
+		return structpb.NewStringValue(formatTime(typeName, x)), nil
+	case []byte:
+		return bytesValue(typeName, x)
+	default:
+		return structpb.NewStringValue(fmt.Sprint(x)), nil
+	}
+}
+
+func intValue(x int64) *structpb.Value {
+	if x > maxSafeInteger || x < -maxSafeInteger {
+		return structpb.NewStringValue(strconv.FormatInt(x, 10))
+	}
+	return structpb.NewNumberValue(float64(x))
+}
+
+func floatValue(x float64) *structpb.Value {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return structpb.NewStringValue(strconv.FormatFloat(x, 'g', -1, 64))
+	}
+	return structpb.NewNumberValue(x)
+}
+
+func formatTime(typeName string, t time.Time) string {
+	switch typeName {
+	case "DATE":
+		return t.Format(time.DateOnly)
+	case "TIME":
+		return t.Format("15:04:05.999999")
+	case "TIMETZ":
+		return t.Format("15:04:05.999999Z07:00")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+// bytesValue xử lý các kiểu lib/pq trả về dạng []byte (numeric, json, bytea, mảng, ...).
+func bytesValue(typeName string, b []byte) (*structpb.Value, error) {
+	switch {
+	case typeName == "BYTEA":
+		return structpb.NewStringValue(base64.StdEncoding.EncodeToString(b)), nil
+	case typeName == "JSON" || typeName == "JSONB":
+		var doc any
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		return structpb.NewValue(doc)
+	case strings.HasPrefix(typeName, "_"):
+		return arrayValue(strings.TrimPrefix(typeName, "_"), b), nil
+	default:
+		return structpb.NewStringValue(string(b)), nil
+	}
+}
+
+// arrayValue parse mảng 1 chiều; mảng nhiều chiều hoặc không parse được
+// thì trả về dạng text gốc.
+func arrayValue(elemType string, b []byte) *structpb.Value {
+	var elems []sql.NullString
+	if err := (pq.GenericArray{A: &elems}).Scan(b); err != nil {
+		return structpb.NewStringValue(string(b))
+	}
+
+	list := make([]*structpb.Value, len(elems))
+	for i, e := range elems {
+		list[i] = arrayElem(elemType, e)
+	}
+	return structpb.NewListValue(&structpb.ListValue{Values: list})
+}
+
+func arrayElem(elemType string, e sql.NullString) *structpb.Value {
+	if !e.Valid {
+		return structpb.NewNullValue()
+	}
+	switch elemType {
+	case "INT2", "INT4", "INT8":
+		if n, err := strconv.ParseInt(e.String, 10, 64); err == nil {
+			return intValue(n)
+		}
+	case "FLOAT4", "FLOAT8":
+		if f, err := strconv.ParseFloat(e.String, 64); err == nil {
+			return floatValue(f)
+		}
+	case "BOOL":
+		return structpb.NewBoolValue(e.String == "t")
+	case "JSON", "JSONB":
+		var doc any
+		if err := json.Unmarshal([]byte(e.String), &doc); err == nil {
+			if v, err := structpb.NewValue(doc); err == nil {
+				return v
+			}
+		}
+	case "BYTEA":
+		// Phần tử bytea trong mảng ở dạng hex "\x..."
+		if b, err := hex.DecodeString(strings.TrimPrefix(e.String, `\x`)); err == nil {
+			return structpb.NewStringValue(base64.StdEncoding.EncodeToString(b))
+		}
+	}
+	return structpb.NewStringValue(e.String)
+}
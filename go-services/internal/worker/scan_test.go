@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func list(vs ...*structpb.Value) *structpb.Value {
+	return structpb.NewListValue(&structpb.ListValue{Values: vs})
+}
+
+func TestColumnValue(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 123_000_000, time.UTC)
+	tz := time.FixedZone("", 7*3600)
+
+	tests := []struct {
+		name     string
+		typeName string
+		in       any
+		want     *structpb.Value
+	}{
+		// NULL
+		{"null int", "INT4", nil, structpb.NewNullValue()},
+		{"null text", "TEXT", nil, structpb.NewNullValue()},
+
+		// Số
+		{"int8", "INT8", int64(42), structpb.NewNumberValue(42)},
+		{"int8 at 2^53", "INT8", int64(1 << 53), structpb.NewNumberValue(1 << 53)},
+		{"int8 above 2^53", "INT8", int64(1<<53 + 1), structpb.NewStringValue("9007199254740993")},
+		{"int8 below -2^53", "INT8", int64(-1<<53 - 1), structpb.NewStringValue("-9007199254740993")},
+		{"float8", "FLOAT8", 1.5, structpb.NewNumberValue(1.5)},
+		{"float8 NaN", "FLOAT8", math.NaN(), structpb.NewStringValue("NaN")},
+		{"float8 +Inf", "FLOAT8", math.Inf(1), structpb.NewStringValue("+Inf")},
+		{"numeric keeps precision", "NUMERIC", []byte("12.3400"), structpb.NewStringValue("12.3400")},
+		{"bool", "BOOL", true, structpb.NewBoolValue(true)},
+		{"text", "TEXT", "hello", structpb.NewStringValue("hello")},
+		{"uuid bytes", "UUID", []byte("6f1c0a6e-8a5b-4c1e-9f3d-2b7a4e0c9d11"), structpb.NewStringValue("6f1c0a6e-8a5b-4c1e-9f3d-2b7a4e0c9d11")},
+
+		// Thời gian
+		{"timestamptz", "TIMESTAMPTZ", ts, structpb.NewStringValue("2024-05-01T10:00:00.123Z")},
+		{"timestamp", "TIMESTAMP", ts, structpb.NewStringValue("2024-05-01T10:00:00.123Z")},
+		{"date", "DATE", ts, structpb.NewStringValue("2024-05-01")},
+		{"time", "TIME", ts, structpb.NewStringValue("10:00:00.123")},
+		{"timetz", "TIMETZ", ts.In(tz), structpb.NewStringValue("17:00:00.123+07:00")},
+
+		// bytea, json
+		{"bytea", "BYTEA", []byte{0x00, 0xff, 0x10}, structpb.NewStringValue("AP8Q")},
+		{"bytea empty", "BYTEA", []byte{}, structpb.NewStringValue("")},
+		{"jsonb object", "JSONB", []byte(`{"a":1,"b":[true,null]}`), mustValue(t, map[string]any{"a": 1.0, "b": []any{true, nil}})},
+
+		// Mảng
+		{"int4[]", "_INT4", []byte("{1,2,NULL}"), list(structpb.NewNumberValue(1), structpb.NewNumberValue(2), structpb.NewNullValue())},
+		{"int8[] above 2^53", "_INT8", []byte("{9007199254740993}"), list(structpb.NewStringValue("9007199254740993"))},
+		{"float8[]", "_FLOAT8", []byte("{1.5,NaN}"), list(structpb.NewNumberValue(1.5), structpb.NewStringValue("NaN"))},
+		{"bool[]", "_BOOL", []byte("{t,f}"), list(structpb.NewBoolValue(true), structpb.NewBoolValue(false))},
+		{"text[] quoted", "_TEXT", []byte(`{a,"b c",NULL,"NULL"}`), list(structpb.NewStringValue("a"), structpb.NewStringValue("b c"), structpb.NewNullValue(), structpb.NewStringValue("NULL"))},
+		{"numeric[]", "_NUMERIC", []byte("{1.10,2}"), list(structpb.NewStringValue("1.10"), structpb.NewStringValue("2"))},
+		{"bytea[]", "_BYTEA", []byte(`{"\\x00ff10"}`), list(structpb.NewStringValue("AP8Q"))},
+		{"jsonb[]", "_JSONB", []byte(`{"{\"a\":1}"}`), list(mustValue(t, map[string]any{"a": 1.0}))},
+		{"empty array", "_INT4", []byte("{}"), list()},
+		{"multi-dimensional falls back to text", "_INT4", []byte("{{1,2},{3,4}}"), structpb.NewStringValue("{{1,2},{3,4}}")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := columnValue(tt.typeName, tt.in)
+			if err != nil {
+				t.Fatalf("columnValue(%q, %v): %v", tt.typeName, tt.in, err)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("columnValue(%q, %v) = %v, want %v", tt.typeName, tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnValueInvalidJSON(t *testing.T) {
+	if _, err := columnValue("JSONB", []byte("{not json")); err == nil {
+		t.Fatal("columnValue(JSONB, invalid) = nil error, want parse error")
+	}
+}
+
+func mustValue(t *testing.T, v any) *structpb.Value {
+	t.Helper()
+	pv, err := structpb.NewValue(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pv
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github/shieldx-bot/laminar/internal/registry"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// newTestServer tạo ComputeServer trên db và Shutdown nó khi test kết thúc.
+func newTestServer(t *testing.T, db *sql.DB, opts ...Option) *ComputeServer {
+	t.Helper()
+	s, err := NewComputeServer(db, append([]Option{WithShards(2)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.Shutdown(ctx)
+	})
+	return s
+}
+
+func mustRegistry(t *testing.T, queries ...*registry.Query) *registry.Registry {
+	t.Helper()
+	reg, err := registry.New(queries...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+func TestExecuteQueryReportsTruncation(t *testing.T) {
+	tests := []struct {
+		name          string
+		dbRows        int
+		maxRows       int
+		wantRecords   int
+		wantTruncated bool
+	}{
+		{"over max_rows", 5, 3, 3, true},
+		{"exactly max_rows", 3, 3, 3, false},
+		{"under max_rows", 2, 3, 2, false},
+		{"no limit", 5, 0, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := rowsDB(t, tt.dbRows)
+			reg := mustRegistry(t, &registry.Query{Name: "top", SQL: "SELECT n FROM t", ReadOnly: true, MaxRows: tt.maxRows})
+			s := newTestServer(t, db, WithQueryRegistry(reg))
+
+			resp, err := s.ExecuteQuery(context.Background(), &pb.TestHTTP3Request{QueryId: "q1", QueryName: "top"})
+			if err != nil {
+				t.Fatalf("ExecuteQuery: %v", err)
+			}
+			if len(resp.GetRecords()) != tt.wantRecords || resp.GetTruncated() != tt.wantTruncated {
+				t.Fatalf("records=%d truncated=%v, want %d %v", len(resp.GetRecords()), resp.GetTruncated(), tt.wantRecords, tt.wantTruncated)
+			}
+			if resp.GetQueryId() != "q1" {
+				t.Fatalf("QueryId = %q, want q1", resp.GetQueryId())
+			}
+			// Rows phải được đóng trước Commit, kể cả khi dừng sớm ở max_rows
+			if n := fake.readOnlyTx.Load(); n != 1 {
+				t.Fatalf("committed read-only transactions = %d, want 1", n)
+			}
+		})
+	}
+}
+
+func TestExecuteQueryTruncationSurvivesCache(t *testing.T) {
+	db, fake := rowsDB(t, 5)
+	reg := mustRegistry(t, &registry.Query{Name: "top", SQL: "SELECT n FROM t", ReadOnly: true, MaxRows: 3, CacheTTL: registry.Duration(time.Minute)})
+	s := newTestServer(t, db, WithQueryRegistry(reg), WithResultCache(CacheConfig{}))
+
+	req := &pb.TestHTTP3Request{QueryId: "same-key", QueryName: "top"}
+	for i := 0; i < 2; i++ {
+		resp, err := s.ExecuteQuery(context.Background(), req)
+		if err != nil {
+			t.Fatalf("ExecuteQuery #%d: %v", i+1, err)
+		}
+		if !resp.GetTruncated() || len(resp.GetRecords()) != 3 {
+			t.Fatalf("ExecuteQuery #%d: records=%d truncated=%v, want 3 true", i+1, len(resp.GetRecords()), resp.GetTruncated())
+		}
+		// ristretto ghi bất đồng bộ: chờ lần Set đầu vào cache
+		for _, sh := range s.shards {
+			sh.cache.c.Wait()
+		}
+	}
+	if n := fake.queries.Load(); n != 1 {
+		t.Fatalf("DB queries = %d, want 1 (second call served from cache)", n)
+	}
+}
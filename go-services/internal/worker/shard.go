@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shard là một worker cùng inbox, bộ đếm tải và cache riêng của nó.
+type shard struct {
+	id    int
+	inbox chan *Job
+	// load = số job shard đang giữ (trong inbox, local queue và đang chạy)
+	load  atomic.Int64
+	cache *resultCache // nil = tắt cache
+
+	// mu bảo vệ queue: worker của shard lấy ở đầu/đuôi theo chế độ,
+	// worker khác chỉ lấy trộm ở đuôi (xem steal).
+	mu    sync.Mutex
+	queue QueuePolicy
+	// queued = queue.Len(), đọc không cần khoá để chọn nạn nhân steal
+	queued atomic.Int64
+	// tuned là snapshot tuning queue đang dùng (giữ sh.mu), xem retune
+	tuned *Tuning
+	// modes là chế độ hiện tại của từng lớp ưu tiên (nil = FIFO), chỉ worker
+	// của shard ghi, metrics/introspection đọc
+	modes [numPriorities]atomic.Pointer[string]
+	// running là job worker đang chạy (nil = rảnh), cho admin API
+	running atomic.Pointer[InFlightJob]
+}
+
+// defaultMode là chế độ của lớp chưa từng đổi chế độ.
+const defaultMode = "FIFO"
+
+func (sh *shard) mode(priority int) string {
+	if m := sh.modes[priority].Load(); m != nil {
+		return *m
+	}
+	return defaultMode
+}
+
+func (sh *shard) setMode(c ModeChange) {
+	if c.Priority >= 0 && int(c.Priority) < numPriorities {
+		mode := c.Mode
+		sh.modes[c.Priority].Store(&mode)
+	}
+}
+
+// MaxShards giới hạn số shard có thể đặt qua Resize.
+const MaxShards = 1024
+
+// newShard tạo shard id (chưa chạy worker), cache chia theo total shard.
+func (s *ComputeServer) newShard(id, total int) (*shard, error) {
+	sh := &shard{
+		id:    id,
+		inbox: make(chan *Job, s.inboxSize),
+		queue: s.newPolicy(),
+		tuned: s.tuning.cur.Load(),
+	}
+	if s.cacheCfg != nil {
+		rc, err := newResultCache(*s.cacheCfg, total)
+		if err != nil {
+			return nil, err
+		}
+		sh.cache = rc
+	}
+	return sh, nil
+}
+
+// runShard chạy worker của sh. Gọi khi đang giữ s.mu (ghi) hoặc trước khi
+// server được dùng, để không Add vào workers sau khi Shutdown đã Wait.
+func (s *ComputeServer) runShard(sh *shard) {
+	s.workers.Add(1)
+	go s.startWorker(sh, s.db)
+}
+
+// ShardCount returns the number of shards currently accepting jobs.
+func (s *ComputeServer) ShardCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.shards)
+}
+
+// Resize grows or shrinks the shard set to n and returns the previous count.
+//
+// Shard ids stay stable: growing adds shards prev..n-1 and shrinking retires
+// the highest ids, so the hash ring only moves keys next to the points of the
+// added or removed shards. A retired shard stops receiving jobs immediately
+// but its worker still runs every job already in its inbox and local queue
+// before exiting, so no accepted job is dropped.
+//
+// Cache capacity is split by the shard count at the time each shard is
+// created; shards kept across a resize keep their existing cache.
+func (s *ComputeServer) Resize(n int) (int, error) {
+	if n <= 0 || n > MaxShards {
+		return 0, status.Errorf(codes.InvalidArgument, "shard count must be between 1 and %d", MaxShards)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev := len(s.shards)
+	if s.closing {
+		return prev, ErrShuttingDown
+	}
+
+	switch {
+	case n > prev:
+		added := make([]*shard, 0, n-prev)
+		for id := prev; id < n; id++ {
+			sh, err := s.newShard(id, n)
+			if err != nil {
+				for _, a := range added {
+					a.cache.c.Close()
+				}
+				return prev, err
+			}
+			added = append(added, sh)
+		}
+		for _, sh := range added {
+			s.runShard(sh)
+		}
+		s.shards = append(s.shards, added...)
+	case n < prev:
+		retired := s.shards[n:]
+		// Giới hạn cap để lần grow sau không ghi đè lên shard đã rút
+		s.shards = s.shards[:n:n]
+		// Đóng inbox dưới write lock như Shutdown: worker xử lý nốt rồi nghỉ
+		for _, sh := range retired {
+			close(sh.inbox)
+		}
+	default:
+		return prev, nil
+	}
+
+	s.ring = newHashRing(n, s.vnodes)
+	return prev, nil
+}
@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github/shieldx-bot/laminar/internal/events"
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// resultShard chờ event worker.results của requestID trên sub và trả về shard
+// đã chạy job. Event của job khác (publish sau khi client đã nhận kết quả) bị bỏ qua.
+func resultShard(t *testing.T, sub *events.Subscription, requestID string) int {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-sub.C:
+			if msg.GetRequestId() != requestID {
+				continue
+			}
+			var ev events.Event
+			if err := json.Unmarshal(msg.GetResultPayload(), &ev); err != nil {
+				t.Fatal(err)
+			}
+			return ev.Shard
+		case <-timeout:
+			t.Fatalf("no result event for %q", requestID)
+			return -1
+		}
+	}
+}
+
+func TestResizeUnderLoad(t *testing.T) {
+	db, _ := rowsDB(t, 1)
+	hub := events.NewHub(4096, events.DropNewest)
+	s := newTestServer(t, db, WithShards(4), WithInboxSize(1000), WithEventHub(hub))
+
+	// Nhiều client gửi liên tục trong lúc đổi số shard lên xuống
+	var (
+		wg        sync.WaitGroup
+		stop      atomic.Bool
+		sent      atomic.Int64
+		succeeded atomic.Int64
+		failures  = make(chan error, 8)
+	)
+	for c := 0; c < 8; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; !stop.Load(); i++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				_, err := s.ExecuteWork(ctx, &pb.WorkRequest{RequestId: fmt.Sprintf("c%d-%d", c, i), SimulatedWorkLoadMs: 1})
+				cancel()
+				sent.Add(1)
+				switch status.Code(err) {
+				case codes.OK:
+					succeeded.Add(1)
+				case codes.ResourceExhausted:
+					// Quá tải lúc enqueue: đã trả lời ngay, không phải job bị mất
+				default:
+					// Job đã vào shard nhưng không nhận được kết quả (vd. DeadlineExceeded)
+					failures <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for _, n := range []int{8, 2, 6, 1, 4} {
+		time.Sleep(20 * time.Millisecond)
+		if _, err := s.Resize(n); err != nil {
+			t.Fatalf("Resize(%d): %v", n, err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	stop.Store(true)
+	wg.Wait()
+	close(failures)
+	for err := range failures {
+		t.Errorf("job lost during resize: %v", err)
+	}
+	if succeeded.Load() == 0 {
+		t.Fatalf("no job succeeded out of %d", sent.Load())
+	}
+
+	// Khi không tải, mỗi key chạy trên shard chính của nó trên vòng hash mới
+	sub := hub.Subscribe(events.TopicResults)
+	defer sub.Close()
+	for _, n := range []int{3, 7} {
+		if _, err := s.Resize(n); err != nil {
+			t.Fatalf("Resize(%d): %v", n, err)
+		}
+		s.mu.RLock()
+		ring := s.ring
+		s.mu.RUnlock()
+		for i := 0; i < 50; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			if _, err := s.ExecuteWork(context.Background(), &pb.WorkRequest{RequestId: key}); err != nil {
+				t.Fatalf("ExecuteWork(%s): %v", key, err)
+			}
+			got := resultShard(t, sub, key)
+			if want := ring.primary(key); got != want || got >= n {
+				t.Fatalf("after Resize(%d): %s ran on shard %d, want %d", n, key, got, want)
+			}
+		}
+	}
+}
+
+func TestResizeDrainsRetiredShards(t *testing.T) {
+	db, _ := rowsDB(t, 1)
+	s := newTestServer(t, db, WithShards(4), WithInboxSize(100))
+
+	// Lấp hàng đợi của mọi shard rồi rút còn 1 shard ngay lập tức
+	results := make(chan error, 200)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			_, err := s.ExecuteWork(context.Background(), &pb.WorkRequest{RequestId: fmt.Sprintf("job-%d", i), SimulatedWorkLoadMs: 1})
+			results <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if prev, err := s.Resize(1); err != nil || prev != 4 {
+		t.Fatalf("Resize(1) = %d, %v, want 4, nil", prev, err)
+	}
+	for i := 0; i < cap(results); i++ {
+		select {
+		case err := <-results:
+			if err != nil && status.Code(err) != codes.ResourceExhausted {
+				t.Fatalf("job %d: %v", i, err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("only %d of %d jobs answered after shrinking", i, cap(results))
+		}
+	}
+	if got := s.ShardCount(); got != 1 {
+		t.Fatalf("ShardCount() = %d, want 1", got)
+	}
+}
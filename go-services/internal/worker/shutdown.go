@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ErrShuttingDown trả về cho job bị từ chối vì ComputeServer đang tắt.
+var ErrShuttingDown = newStatusError(codes.Unavailable, ReasonShuttingDown, "compute server is shutting down", retryShutdown)
+
+// Shutdown stops accepting jobs and waits for the workers to drain their
+// queues. Jobs already queued keep running until ctx expires; after that every
+// job still waiting is answered with ErrShuttingDown and Shutdown returns
+// ctx.Err() without waiting for jobs that are mid-execution.
+// Calling Shutdown more than once is safe.
+func (s *ComputeServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closing {
+		s.closing = true
+		// Đóng inbox dưới write lock: dispatch giữ read lock khi gửi nên
+		// không bao giờ gửi vào channel đã đóng.
+		for _, sh := range s.shards {
+			close(sh.inbox)
+		}
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.abortOnce.Do(func() { close(s.abort) })
+		return ctx.Err()
+	}
+}
+
+// aborted reports whether Shutdown gave up waiting, in which case queued jobs
+// are rejected instead of executed.
+func (s *ComputeServer) aborted() bool {
+	select {
+	case <-s.abort:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+// waitFor chờ cond đúng, tối đa 5s.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// queueWork gửi n WorkRequest mô phỏng tải load lên s, chờ tới khi cả n job
+// đã nằm trong shard, và trả về channel nhận lỗi của từng job.
+func queueWork(t *testing.T, s *ComputeServer, n int, load time.Duration) <-chan error {
+	t.Helper()
+	results := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := s.ExecuteWork(context.Background(), &pb.WorkRequest{
+				RequestId:           fmt.Sprintf("job-%d", i),
+				SimulatedWorkLoadMs: int32(load.Milliseconds()),
+			})
+			results <- err
+		}()
+	}
+	waitFor(t, "jobs to be queued", func() bool {
+		var total int64
+		for _, sh := range s.shards {
+			total += sh.load.Load()
+		}
+		return total == int64(n)
+	})
+	return results
+}
+
+func TestShutdownDrainsQueuedJobs(t *testing.T) {
+	db, _ := rowsDB(t, 1)
+	s := newTestServer(t, db, WithShards(1))
+	const jobs = 20
+	results := queueWork(t, s, jobs, 2*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	// Shutdown chỉ trả nil khi mọi worker đã chạy hết hàng đợi
+	for i := 0; i < jobs; i++ {
+		if err := <-results; err != nil {
+			t.Errorf("queued job failed: %v", err)
+		}
+	}
+
+	_, err := s.ExecuteWork(context.Background(), &pb.WorkRequest{RequestId: "late"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("ExecuteWork after Shutdown = %v, want Unavailable", err)
+	}
+}
+
+func TestShutdownRejectsJobsAfterDeadline(t *testing.T) {
+	db, _ := rowsDB(t, 1)
+	s := newTestServer(t, db, WithShards(1))
+	const (
+		jobs = 10
+		load = 200 * time.Millisecond
+	)
+	results := queueWork(t, s, jobs, load)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want DeadlineExceeded", err)
+	}
+
+	// Job đang chạy được làm nốt; job còn chờ bị từ chối ngay thay vì treo
+	// (chạy tuần tự cả hàng đợi mất jobs*load = 2s)
+	var ok, unavailable int
+	for i := 0; i < jobs; i++ {
+		select {
+		case err := <-results:
+			switch status.Code(err) {
+			case codes.OK:
+				ok++
+			case codes.Unavailable:
+				unavailable++
+			default:
+				t.Fatalf("job %d: %v, want OK or Unavailable", i, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of %d jobs answered after Shutdown gave up", i, jobs)
+		}
+	}
+	if elapsed := time.Since(start); elapsed >= jobs*load/2 {
+		t.Errorf("pending jobs answered after %v, want well under %v", elapsed, jobs*load)
+	}
+	if ok > 1 || unavailable < jobs-1 {
+		t.Fatalf("%d ok and %d unavailable, want at most the running job ok", ok, unavailable)
+	}
+}
@@ -0,0 +1,85 @@
+package worker
+
+// DefaultStealThreshold: chỉ lấy trộm việc của shard có từ chừng này job đang
+// chờ trong local queue. Dưới ngưỡng, job ở lại shard của nó để giữ cache
+// locality; trên ngưỡng, dùng core rảnh quan trọng hơn.
+const DefaultStealThreshold = 16
+
+// StealStats là số liệu cộng dồn của work stealing.
+type StealStats struct {
+	// Steals là số job đã được worker khác lấy về chạy.
+	Steals uint64 `json:"steals"`
+	// Misses là số lần chọn được nạn nhân nhưng nó đã xuống dưới ngưỡng.
+	Misses uint64 `json:"misses"`
+}
+
+// WithStealThreshold lets an idle worker take jobs from the tail of the most
+// loaded shard once that shard has at least n jobs waiting in its local
+// queue. Lower values spread skewed keys across more cores at the cost of
+// result-cache locality. n <= 0 disables work stealing. It can be changed
+// later with UpdateTuning.
+func WithStealThreshold(n int) Option {
+	return func(s *ComputeServer) {
+		s.setInitial(func(t *Tuning) { t.StealThreshold = n })
+	}
+}
+
+// StealStats returns work stealing counters.
+func (s *ComputeServer) StealStats() StealStats {
+	return StealStats{
+		Steals: s.steals.Load(),
+		Misses: s.stealMisses.Load(),
+	}
+}
+
+// steal lấy một job ở đuôi local queue của shard đang chờ nhiều việc nhất
+// (ít nhất t.StealThreshold) và chuyển phần tải của job sang thief.
+// Trả về nil nếu không có shard nào vượt ngưỡng.
+func (s *ComputeServer) steal(thief *shard, t *Tuning) *Job {
+	if t.StealThreshold <= 0 {
+		return nil
+	}
+
+	threshold := int64(t.StealThreshold)
+	var victim *shard
+	s.mu.RLock()
+	for _, sh := range s.shards {
+		if sh != thief && sh.queued.Load() >= threshold && (victim == nil || sh.queued.Load() > victim.queued.Load()) {
+			victim = sh
+		}
+	}
+	s.mu.RUnlock()
+	if victim == nil {
+		return nil
+	}
+
+	var job *Job
+	victim.mu.Lock()
+	if int64(victim.queue.Len()) >= threshold {
+		job = victim.queue.StealTail()
+	}
+	victim.queued.Store(int64(victim.queue.Len()))
+	victim.mu.Unlock()
+
+	if job == nil {
+		s.stealMisses.Add(1)
+		return nil
+	}
+	victim.load.Add(-1)
+	thief.load.Add(1)
+	s.steals.Add(1)
+	// Nạn nhân vẫn còn quá tải: gọi thêm một worker rảnh nữa
+	s.signalSteal(victim, t)
+	return job
+}
+
+// signalSteal đánh thức một worker đang ngủ nếu sh có đủ việc để chia.
+func (s *ComputeServer) signalSteal(sh *shard, t *Tuning) {
+	if t.StealThreshold <= 0 || sh.queued.Load() < int64(t.StealThreshold) {
+		return
+	}
+	select {
+	case s.stealWake <- struct{}{}:
+	default:
+	}
+}
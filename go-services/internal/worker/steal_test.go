@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.SpanRecorder
+)
+
+// recordSpans đặt tracer provider global ghi span vào bộ nhớ. tracer của
+// package chỉ bám vào provider đặt lần đầu, nên mọi test dùng chung một recorder.
+func recordSpans() *tracetest.SpanRecorder {
+	spansOnce.Do(func() {
+		spans = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	})
+	return spans
+}
+
+// keysOnShard trả về n key có shard chính là shard trên ring.
+func keysOnShard(ring *hashRing, shard, n int) []string {
+	var keys []string
+	for i := 0; len(keys) < n; i++ {
+		if key := fmt.Sprintf("key-%d", i); ring.primary(key) == shard {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestIdleShardStealsFromOverloadedShard(t *testing.T) {
+	sr := recordSpans()
+	start := time.Now()
+	db, _ := rowsDB(t, 1)
+	reg := prometheus.NewRegistry()
+	// loadFactor lớn: mọi key ở lại shard chính, không tràn sang shard bên cạnh
+	s := newTestServer(t, db, WithShards(2), WithLoadFactor(1000), WithStealThreshold(2), WithMetrics(reg))
+
+	// Mọi job dồn vào shard 0, shard 1 rảnh
+	keys := keysOnShard(s.ring, 0, 30)
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.ExecuteWork(context.Background(), &pb.WorkRequest{RequestId: key, SimulatedWorkLoadMs: 10}); err != nil {
+				t.Errorf("ExecuteWork(%s): %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	steals := s.StealStats().Steals
+	if steals == 0 {
+		t.Fatal("idle shard stole no jobs")
+	}
+	if got := gatherCounter(t, reg, MetricsNamespace+"_worker_steals_total"); got != float64(steals) {
+		t.Errorf("steals metric = %v, want %d", got, steals)
+	}
+
+	// Event dequeue của job bị lấy trộm mang laminar.stolen=true và shard của worker đã lấy
+	want := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		want[key] = true
+	}
+	var stolen uint64
+	for _, span := range sr.Ended() {
+		// Recorder dùng chung: bỏ span của test hay lần chạy trước
+		if span.Name() != "ComputeServer.ExecuteWork" || span.StartTime().Before(start) {
+			continue
+		}
+		attrs := map[string]any{}
+		for _, kv := range span.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.AsInterface()
+		}
+		if key, _ := attrs[string(attrQueryID)].(string); !want[key] {
+			continue
+		}
+		for _, ev := range span.Events() {
+			if ev.Name != "dequeue" {
+				continue
+			}
+			ea := map[string]any{}
+			for _, kv := range ev.Attributes {
+				ea[string(kv.Key)] = kv.Value.AsInterface()
+			}
+			isStolen, _ := ea[string(attrStolen)].(bool)
+			shard, _ := ea[string(attrShard)].(int64)
+			if isStolen {
+				stolen++
+			}
+			if wantShard := map[bool]int64{false: 0, true: 1}[isStolen]; shard != wantShard {
+				t.Errorf("dequeue event stolen=%v on shard %d, want shard %d", isStolen, shard, wantShard)
+			}
+		}
+	}
+	if stolen != steals {
+		t.Errorf("%d dequeue events with stolen=true, want %d", stolen, steals)
+	}
+}
+
+// gatherCounter đọc giá trị của counter không label name từ reg.
+func gatherCounter(t *testing.T, reg *prometheus.Registry, name string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() == name {
+			return f.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not registered", name)
+	return 0
+}
+
+func TestStealThresholdDisablesStealing(t *testing.T) {
+	db, _ := rowsDB(t, 1)
+	s := newTestServer(t, db, WithShards(2), WithLoadFactor(1000), WithStealThreshold(0))
+
+	var wg sync.WaitGroup
+	for _, key := range keysOnShard(s.ring, 0, 10) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := s.ExecuteWork(ctx, &pb.WorkRequest{RequestId: key, SimulatedWorkLoadMs: 2}); err != nil {
+				t.Errorf("ExecuteWork(%s): %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if st := s.StealStats(); st.Steals != 0 {
+		t.Fatalf("StealStats = %+v with stealing disabled, want no steals", st)
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer lấy từ provider global, nên đổi provider sau khi khởi tạo vẫn có hiệu lực.
+var tracer = otel.Tracer("github/shieldx-bot/laminar/internal/worker")
+
+// Attribute key của các span/event do worker tạo.
+const (
+	attrQueryID   = attribute.Key("laminar.query_id")
+	attrQueryName = attribute.Key("laminar.query_name")
+	attrShard     = attribute.Key("laminar.shard")
+	attrPriority  = attribute.Key("laminar.priority")
+	attrQueueMode = attribute.Key("laminar.queue.mode")
+	attrQueueLen  = attribute.Key("laminar.queue.length")
+	attrQueueWait = attribute.Key("laminar.queue.wait_ms")
+	attrStolen    = attribute.Key("laminar.stolen")
+	attrJobKind   = attribute.Key("laminar.job.kind")
+	attrTruncated = attribute.Key("laminar.result.truncated")
+)
+
+// endSpan ghi lỗi (nếu có) vào span rồi kết thúc span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceEnqueue thêm event "enqueue" vào span của request khi job vào inbox.
+func traceEnqueue(job *Job, sh *shard) {
+	span := trace.SpanFromContext(job.Ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent("enqueue", trace.WithTimestamp(job.EnqueuedAt), trace.WithAttributes(
+		attrShard.Int(sh.id),
+		attrQueueLen.Int(len(sh.inbox)+int(sh.queued.Load())),
+	))
+}
+
+// traceDequeue thêm event "dequeue" vào span của request khi worker lấy job
+// ra, kèm chế độ hàng đợi (FIFO/LIFO/...) của lớp ưu tiên lúc pop.
+func traceDequeue(job *Job, sh *shard, stolen bool) {
+	span := trace.SpanFromContext(job.Ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent("dequeue", trace.WithAttributes(
+		attrShard.Int(sh.id),
+		attrPriority.Int(int(job.Priority)),
+		attrQueueMode.String(sh.mode(int(job.Priority))),
+		attrQueueLen.Int(int(sh.queued.Load())),
+		attrQueueWait.Float64(float64(time.Since(job.EnqueuedAt).Microseconds())/1000),
+		attrStolen.Bool(stolen),
+	))
+}
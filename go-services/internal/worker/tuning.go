@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Tuning holds the worker parameters that can change while the server runs.
+// The server keeps an immutable snapshot behind an atomic pointer; workers
+// load it once per loop iteration, so a change applies to the next job each
+// worker picks up without any lock on the hot path.
+type Tuning struct {
+	// Ngưỡng bật/tắt LIFO của QueuePolicy (áp dụng cho policy có Tune)
+	HighWaterMark int `json:"high_water_mark"`
+	LowWaterMark  int `json:"low_water_mark"`
+	// MaxInboxDepth: pickShard bỏ qua shard có inbox dài hơn (thay cho
+	// TotalMaxProcessOnWorker)
+	MaxInboxDepth int `json:"max_inbox_depth"`
+	// StealThreshold: số job chờ tối thiểu để worker rảnh lấy trộm; <= 0 tắt
+	// việc chuyển job sang shard khác (thay cho ChangePoolJob)
+	StealThreshold int `json:"steal_threshold"`
+	// MaxQueueAge: job chờ lâu hơn bị drop khi lấy ra (0 = tắt)
+	MaxQueueAge time.Duration `json:"max_queue_age"`
+}
+
+// DefaultMaxInboxDepth: inbox dài hơn thì shard coi như đầy.
+const DefaultMaxInboxDepth = 80
+
+// DefaultTuning returns the tuning a ComputeServer starts with.
+func DefaultTuning() Tuning {
+	return Tuning{
+		HighWaterMark:  HighWaterMark,
+		LowWaterMark:   LowWaterMark,
+		MaxInboxDepth:  DefaultMaxInboxDepth,
+		StealThreshold: DefaultStealThreshold,
+		MaxQueueAge:    DefaultMaxQueueAge,
+	}
+}
+
+// Validate reports whether t can be applied.
+func (t Tuning) Validate() error {
+	switch {
+	case t.LowWaterMark < 0 || t.LowWaterMark >= t.HighWaterMark:
+		return fmt.Errorf("low water mark (%d) must be in [0, high water mark (%d))", t.LowWaterMark, t.HighWaterMark)
+	case t.MaxInboxDepth <= 0:
+		return fmt.Errorf("max inbox depth must be positive")
+	case t.MaxQueueAge < 0:
+		return fmt.Errorf("max queue age must not be negative")
+	}
+	return nil
+}
+
+// diff liệt kê các field khác nhau dạng "name old -> new".
+func (t Tuning) diff(next Tuning) []string {
+	var out []string
+	add := func(name string, old, cur any) {
+		if old != cur {
+			out = append(out, fmt.Sprintf("%s %v -> %v", name, old, cur))
+		}
+	}
+	add("high_water_mark", t.HighWaterMark, next.HighWaterMark)
+	add("low_water_mark", t.LowWaterMark, next.LowWaterMark)
+	add("max_inbox_depth", t.MaxInboxDepth, next.MaxInboxDepth)
+	add("steal_threshold", t.StealThreshold, next.StealThreshold)
+	add("max_queue_age", t.MaxQueueAge, next.MaxQueueAge)
+	return out
+}
+
+// TunablePolicy is implemented by queue policies whose parameters follow the
+// server's Tuning. The worker calls Tune with the shard lock held whenever it
+// sees a new snapshot.
+type TunablePolicy interface {
+	Tune(t Tuning)
+}
+
+// TuningStats là giá trị hiện tại cùng số liệu cộng dồn của các lần đổi tuning.
+type TuningStats struct {
+	Tuning
+	// Updates là số lần áp dụng thành công, Rejected là số lần bị từ chối
+	// vì giá trị không hợp lệ.
+	Updates    uint64    `json:"updates"`
+	Rejected   uint64    `json:"rejected"`
+	LastUpdate time.Time `json:"last_update"`
+}
+
+// tuningState là phần của ComputeServer giữ snapshot tuning.
+type tuningState struct {
+	cur atomic.Pointer[Tuning]
+	// mu tuần tự hoá các lần ghi để update từng phần không mất nhau
+	mu         sync.Mutex
+	updates    atomic.Uint64
+	rejected   atomic.Uint64
+	lastUpdate atomic.Int64 // UnixNano, 0 = chưa đổi lần nào
+}
+
+// WithTuning sets the initial tuning. Later options such as
+// WithStealThreshold override single fields.
+func WithTuning(t Tuning) Option {
+	return func(s *ComputeServer) {
+		s.tuning.cur.Store(&t)
+	}
+}
+
+// setInitial đổi một field của snapshot khi đang áp Option.
+func (s *ComputeServer) setInitial(fn func(*Tuning)) {
+	t := *s.tuning.cur.Load()
+	fn(&t)
+	s.tuning.cur.Store(&t)
+}
+
+// Tuning returns the tuning currently in effect.
+func (s *ComputeServer) Tuning() Tuning {
+	return *s.tuning.cur.Load()
+}
+
+// UpdateTuning applies fn to a copy of the current tuning and, if the result
+// is valid, swaps it in and logs every changed value. It returns the previous
+// and the resulting tuning. Invalid results are rejected with InvalidArgument
+// and leave the running tuning untouched.
+func (s *ComputeServer) UpdateTuning(fn func(*Tuning)) (prev, next Tuning, err error) {
+	s.tuning.mu.Lock()
+	defer s.tuning.mu.Unlock()
+
+	prev = *s.tuning.cur.Load()
+	next = prev
+	fn(&next)
+	if err := next.Validate(); err != nil {
+		s.tuning.rejected.Add(1)
+		return prev, prev, status.Errorf(codes.InvalidArgument, "invalid tuning: %v", err)
+	}
+
+	changes := prev.diff(next)
+	if len(changes) == 0 {
+		return prev, next, nil
+	}
+	s.tuning.cur.Store(&next)
+	s.tuning.updates.Add(1)
+	s.tuning.lastUpdate.Store(time.Now().UnixNano())
+	s.log.Info("worker tuning updated", slog.String("changes", strings.Join(changes, ", ")))
+
+	// Ngưỡng steal có thể vừa hạ: đánh thức một worker rảnh để thử lại
+	select {
+	case s.stealWake <- struct{}{}:
+	default:
+	}
+	return prev, next, nil
+}
+
+// TuningStats returns the current tuning and update counters.
+func (s *ComputeServer) TuningStats() TuningStats {
+	st := TuningStats{
+		Tuning:   s.Tuning(),
+		Updates:  s.tuning.updates.Load(),
+		Rejected: s.tuning.rejected.Load(),
+	}
+	if ns := s.tuning.lastUpdate.Load(); ns != 0 {
+		st.LastUpdate = time.Unix(0, ns)
+	}
+	return st
+}
+
+// retune áp snapshot t vào policy của sh nếu shard chưa thấy nó. Gọi khi
+// đang giữ sh.mu.
+func (sh *shard) retune(t *Tuning) {
+	if sh.tuned == t {
+		return
+	}
+	sh.tuned = t
+	if p, ok := sh.queue.(TunablePolicy); ok {
+		p.Tune(*t)
+	}
+}
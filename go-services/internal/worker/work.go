@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+
+	pb "github/shieldx-bot/laminar/pb"
+)
+
+const (
+	// PriorityNormal and PriorityHigh mirror WorkRequest.priority.
+	PriorityNormal int32 = 0
+	PriorityHigh   int32 = 1
+
+	// MaxSimulatedWork caps simulated_work_load_ms so a single request cannot
+	// pin a worker shard indefinitely.
+	MaxSimulatedWork = 60 * time.Second
+)
+
+// ExecuteWork runs a DB-free WorkRequest through the same sharded queue as
+// ExecuteQuery. The response separates the time spent waiting in the shard
+// queue from the time spent inside the worker.
+func (s *ComputeServer) ExecuteWork(ctx context.Context, req *pb.WorkRequest) (resp *pb.WorkResponse, err error) {
+	ctx, span := tracer.Start(ctx, "ComputeServer.ExecuteWork", trace.WithAttributes(attrQueryID.String(req.GetRequestId())))
+	defer func() { endSpan(span, err) }()
+
+	load := time.Duration(req.GetSimulatedWorkLoadMs()) * time.Millisecond
+	if load < 0 || load > MaxSimulatedWork {
+		return nil, newStatusError(codes.InvalidArgument, ReasonInvalidQuery,
+			fmt.Sprintf("simulated_work_load_ms must be between 0 and %d", MaxSimulatedWork.Milliseconds()), 0)
+	}
+
+	job := &Job{
+		Ctx:      ctx,
+		QueryId:  req.GetRequestId(),
+		Work:     req,
+		Priority: normalizePriority(req.GetPriority()),
+		RespChan: make(chan *JobResult, 1),
+	}
+
+	result, err := s.dispatch(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Work, nil
+}
+
+// runWork burns CPU or sleeps for the requested simulated load and builds the
+// response. The caller's context aborts the work early.
+func runWork(job *Job, dequeuedAt time.Time) (*pb.WorkResponse, error) {
+	req := job.Work
+	load := time.Duration(req.GetSimulatedWorkLoadMs()) * time.Millisecond
+
+	var err error
+	switch req.GetKind() {
+	case pb.WorkKind_WORK_KIND_CPU:
+		err = burnCPU(job.Ctx, load)
+	default:
+		err = sleepCtx(job.Ctx, load)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	done := time.Now()
+	wait := dequeuedAt.Sub(job.EnqueuedAt)
+	service := done.Sub(dequeuedAt)
+	return &pb.WorkResponse{
+		RequestId:              req.GetRequestId(),
+		Success:                true,
+		ServerProcessingTimeNs: (wait + service).Nanoseconds(),
+		QueueWaitTimeNs:        wait.Nanoseconds(),
+		ServiceTimeNs:          service.Nanoseconds(),
+		ResultPayload:          req.GetPayload(),
+	}, nil
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// burnCPU spins until d has elapsed, checking the context every few thousand
+// iterations so a cancelled request releases the core.
+func burnCPU(ctx context.Context, d time.Duration) error {
+	deadline := time.Now().Add(d)
+	x := uint64(1)
+	for i := 0; ; i++ {
+		x = x*6364136223846793005 + 1442695040888963407
+		if i%4096 == 0 {
+			if time.Now().After(deadline) {
+				break
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	atomic.StoreUint64(&sink, x)
+	return nil
+}
+
+// sink keeps the compiler from eliminating the burnCPU loop.
+var sink uint64
+
+func normalizePriority(p int32) int32 {
+	if p >= PriorityHigh {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinLogger replaces gin's default access log: 5xx are logged at error, 4xx
+// at warn and successes through s. Put it after tracing.Middleware so every
+// line carries the request's trace id.
+func GinLogger(l *slog.Logger, s *Sampler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		args := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			args = append(args, slog.String("error", c.Errors.String()))
+		}
+		ctx := c.Request.Context()
+		switch {
+		case status >= 500:
+			l.ErrorContext(ctx, "http request", args...)
+		case status >= 400:
+			l.WarnContext(ctx, "http request", args...)
+		default:
+			Success(ctx, l, s, "http request", args...)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package logging builds the log/slog logger shared by the Laminar binaries.
+// Every record logged with a context carries the trace and span ids of that
+// context and the request attributes attached with With (query_id, shard,
+// queue_mode, ...). The level can be changed while the process runs, and hot
+// path success logs go through a Sampler so they stay affordable at high RPS.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github/shieldx-bot/laminar/config"
+)
+
+// Tên attribute chuẩn, dùng chung để log của các binary join được với nhau.
+const (
+	KeyQueryID   = "query_id"
+	KeyShard     = "shard"
+	KeyQueueMode = "queue_mode"
+	KeyTraceID   = "trace_id"
+	KeySpanID    = "span_id"
+	KeyService   = "service"
+)
+
+// level là level của mọi logger tạo bởi New; đổi bằng SetLevel.
+var level slog.LevelVar
+
+// New returns a logger writing cfg.Format ("json" or "text") to stderr at
+// cfg.Level, tagged with service.
+func New(cfg config.LogConfig, service string) (*slog.Logger, error) {
+	return newLogger(os.Stderr, cfg, service)
+}
+
+func newLogger(w io.Writer, cfg config.LogConfig, service string) (*slog.Logger, error) {
+	if err := SetLevel(cfg.Level); err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: &level}
+	var h slog.Handler
+	switch cfg.Format {
+	case "", "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("log format %q is not json or text", cfg.Format)
+	}
+	return slog.New(&contextHandler{Handler: h}).With(KeyService, service), nil
+}
+
+// SetLevel changes the level of every logger built by New. It accepts the
+// slog names (debug, info, warn, error), case-insensitively.
+func SetLevel(name string) error {
+	if name == "" {
+		name = "info"
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(name))); err != nil {
+		return fmt.Errorf("log level %q: %w", name, err)
+	}
+	if l != level.Level() {
+		level.Set(l)
+	}
+	return nil
+}
+
+// Level returns the current level.
+func Level() slog.Level {
+	return level.Level()
+}
+
+type ctxKey struct{}
+
+// With returns a context whose log records carry attrs in addition to those
+// already attached to ctx.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(prev)+len(attrs))
+	merged = append(merged, prev...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// contextHandler thêm trace id và các attribute gắn bằng With vào record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if attrs, ok := ctx.Value(ctxKey{}).([]slog.Attr); ok {
+			r.AddAttrs(attrs...)
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String(KeyTraceID, sc.TraceID().String()), slog.String(KeySpanID, sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Sampler lets one in every n hot-path events through. A nil Sampler or
+// n <= 1 lets every event through.
+type Sampler struct {
+	n     uint64
+	count atomic.Uint64
+}
+
+// NewSampler returns a sampler keeping one event in n.
+func NewSampler(n int) *Sampler {
+	if n < 1 {
+		n = 1
+	}
+	return &Sampler{n: uint64(n)}
+}
+
+// Sample reports whether the current event should be logged.
+func (s *Sampler) Sample() bool {
+	if s == nil || s.n <= 1 {
+		return true
+	}
+	return s.count.Add(1)%s.n == 1
+}
+
+// Rate là n của sampler, ghi vào log để biết mỗi dòng đại diện bao nhiêu event.
+func (s *Sampler) Rate() int {
+	if s == nil {
+		return 1
+	}
+	return int(s.n)
+}
+
+// Success logs a hot-path success at info level when s samples it. The record
+// carries sample_rate so counts can be scaled back.
+func Success(ctx context.Context, l *slog.Logger, s *Sampler, msg string, args ...any) {
+	if !l.Enabled(ctx, slog.LevelInfo) || !s.Sample() {
+		return
+	}
+	l.Log(ctx, slog.LevelInfo, msg, append(args, slog.Int("sample_rate", s.Rate()))...)
+}
@@ -9,7 +9,9 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -21,6 +23,54 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type WorkKind int32
+
+const (
+	// Ngủ trong simulated_work_load_ms, giải phóng CPU (giống chờ DB/IO).
+	WorkKind_WORK_KIND_SLEEP WorkKind = 0
+	// Busy-loop trong simulated_work_load_ms, chiếm trọn một core.
+	WorkKind_WORK_KIND_CPU WorkKind = 1
+)
+
+// Enum value maps for WorkKind.
+var (
+	WorkKind_name = map[int32]string{
+		0: "WORK_KIND_SLEEP",
+		1: "WORK_KIND_CPU",
+	}
+	WorkKind_value = map[string]int32{
+		"WORK_KIND_SLEEP": 0,
+		"WORK_KIND_CPU":   1,
+	}
+)
+
+func (x WorkKind) Enum() *WorkKind {
+	p := new(WorkKind)
+	*p = x
+	return p
+}
+
+func (x WorkKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WorkKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_laminar_proto_enumTypes[0].Descriptor()
+}
+
+func (WorkKind) Type() protoreflect.EnumType {
+	return &file_api_proto_laminar_proto_enumTypes[0]
+}
+
+func (x WorkKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WorkKind.Descriptor instead.
+func (WorkKind) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{0}
+}
+
 type WorkRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -39,6 +89,8 @@ type WorkRequest struct {
 	// Padding để test Cache Locality (dựa trên source 1468)
 	// Thêm dữ liệu rác để object align với 64-byte cache line.
 	Padding []byte `protobuf:"bytes,5,opt,name=padding,proto3" json:"padding,omitempty"`
+	// Cách worker "tiêu thụ" simulated_work_load_ms: ngủ (I/O bound) hay đốt CPU (CPU bound).
+	Kind WorkKind `protobuf:"varint,6,opt,name=kind,proto3,enum=laminar.WorkKind" json:"kind,omitempty"`
 }
 
 func (x *WorkRequest) Reset() {
@@ -108,6 +160,13 @@ func (x *WorkRequest) GetPadding() []byte {
 	return nil
 }
 
+func (x *WorkRequest) GetKind() WorkKind {
+	if x != nil {
+		return x.Kind
+	}
+	return WorkKind_WORK_KIND_SLEEP
+}
+
 type WorkResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -116,9 +175,22 @@ type WorkResponse struct {
 	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	Success   bool   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
 	// Thời gian xử lý thực tế tại Server (để tính toán Overhead của hàng đợi)
+	// = queue_wait_time_ns + service_time_ns
 	ServerProcessingTimeNs int64 `protobuf:"varint,3,opt,name=server_processing_time_ns,json=serverProcessingTimeNs,proto3" json:"server_processing_time_ns,omitempty"`
 	// Dữ liệu trả về
 	ResultPayload []byte `protobuf:"bytes,4,opt,name=result_payload,json=resultPayload,proto3" json:"result_payload,omitempty"`
+	// Thời gian job nằm chờ trong hàng đợi của shard (từ lúc enqueue tới lúc worker lấy ra)
+	QueueWaitTimeNs int64 `protobuf:"varint,5,opt,name=queue_wait_time_ns,json=queueWaitTimeNs,proto3" json:"queue_wait_time_ns,omitempty"`
+	// Thời gian worker thực sự xử lý job
+	ServiceTimeNs int64 `protobuf:"varint,6,opt,name=service_time_ns,json=serviceTimeNs,proto3" json:"service_time_ns,omitempty"`
+	// Lý do thất bại khi success = false (stream không bị đóng vì lỗi của một request)
+	ErrorMessage string `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// Topic của event khi message được đẩy qua SubscribeToEvents;
+	// khi đó result_payload là JSON mô tả event.
+	Topic string `protobuf:"bytes,8,opt,name=topic,proto3" json:"topic,omitempty"`
+	// Reason trong ErrorInfo của lỗi (xem package errinfo) khi success = false,
+	// để client stream phân loại lỗi như với RPC unary.
+	ErrorReason string `protobuf:"bytes,9,opt,name=error_reason,json=errorReason,proto3" json:"error_reason,omitempty"`
 }
 
 func (x *WorkResponse) Reset() {
@@ -181,6 +253,41 @@ func (x *WorkResponse) GetResultPayload() []byte {
 	return nil
 }
 
+func (x *WorkResponse) GetQueueWaitTimeNs() int64 {
+	if x != nil {
+		return x.QueueWaitTimeNs
+	}
+	return 0
+}
+
+func (x *WorkResponse) GetServiceTimeNs() int64 {
+	if x != nil {
+		return x.ServiceTimeNs
+	}
+	return 0
+}
+
+func (x *WorkResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *WorkResponse) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *WorkResponse) GetErrorReason() string {
+	if x != nil {
+		return x.ErrorReason
+	}
+	return ""
+}
+
 type EventSubscription struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -233,9 +340,20 @@ type TestHTTP3Request struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	QueryId  string `protobuf:"bytes,1,opt,name=QueryId,proto3" json:"QueryId,omitempty"`
+	QueryId string `protobuf:"bytes,1,opt,name=QueryId,proto3" json:"QueryId,omitempty"`
+	// SQL thô. Deprecated: dùng query_name hoặc query_template + args.
+	// Chỉ chạy khi khớp một câu đã đăng ký trong registry, trừ khi server
+	// bật registry.allow_ad_hoc_sql.
 	QuerySQL string `protobuf:"bytes,3,opt,name=QuerySQL,proto3" json:"QuerySQL,omitempty"`
 	Payload  []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Tên query đã đăng ký sẵn ở server (ưu tiên hơn query_template).
+	QueryName string `protobuf:"bytes,4,opt,name=query_name,json=queryName,proto3" json:"query_name,omitempty"`
+	// Câu SQL có placeholder $1, $2, ... Giá trị truyền qua args, driver tự bind.
+	// Như QuerySQL: phải khớp một câu trong registry nếu server không bật
+	// registry.allow_ad_hoc_sql.
+	QueryTemplate string `protobuf:"bytes,5,opt,name=query_template,json=queryTemplate,proto3" json:"query_template,omitempty"`
+	// Tham số vị trí: args[0] -> $1, args[1] -> $2, ...
+	Args []*QueryArg `protobuf:"bytes,6,rep,name=args,proto3" json:"args,omitempty"`
 }
 
 func (x *TestHTTP3Request) Reset() {
@@ -291,6 +409,179 @@ func (x *TestHTTP3Request) GetPayload() []byte {
 	return nil
 }
 
+func (x *TestHTTP3Request) GetQueryName() string {
+	if x != nil {
+		return x.QueryName
+	}
+	return ""
+}
+
+func (x *TestHTTP3Request) GetQueryTemplate() string {
+	if x != nil {
+		return x.QueryTemplate
+	}
+	return ""
+}
+
+func (x *TestHTTP3Request) GetArgs() []*QueryArg {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+// Một tham số có kiểu cho query_template.
+type QueryArg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Value:
+	//	*QueryArg_NullValue
+	//	*QueryArg_StringValue
+	//	*QueryArg_IntValue
+	//	*QueryArg_DoubleValue
+	//	*QueryArg_BoolValue
+	//	*QueryArg_BytesValue
+	//	*QueryArg_TimestampValue
+	Value isQueryArg_Value `protobuf_oneof:"value"`
+}
+
+func (x *QueryArg) Reset() {
+	*x = QueryArg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_laminar_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryArg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryArg) ProtoMessage() {}
+
+func (x *QueryArg) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_laminar_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryArg.ProtoReflect.Descriptor instead.
+func (*QueryArg) Descriptor() ([]byte, []int) {
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{4}
+}
+
+func (m *QueryArg) GetValue() isQueryArg_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *QueryArg) GetNullValue() bool {
+	if x, ok := x.GetValue().(*QueryArg_NullValue); ok {
+		return x.NullValue
+	}
+	return false
+}
+
+func (x *QueryArg) GetStringValue() string {
+	if x, ok := x.GetValue().(*QueryArg_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (x *QueryArg) GetIntValue() int64 {
+	if x, ok := x.GetValue().(*QueryArg_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (x *QueryArg) GetDoubleValue() float64 {
+	if x, ok := x.GetValue().(*QueryArg_DoubleValue); ok {
+		return x.DoubleValue
+	}
+	return 0
+}
+
+func (x *QueryArg) GetBoolValue() bool {
+	if x, ok := x.GetValue().(*QueryArg_BoolValue); ok {
+		return x.BoolValue
+	}
+	return false
+}
+
+func (x *QueryArg) GetBytesValue() []byte {
+	if x, ok := x.GetValue().(*QueryArg_BytesValue); ok {
+		return x.BytesValue
+	}
+	return nil
+}
+
+func (x *QueryArg) GetTimestampValue() *timestamppb.Timestamp {
+	if x, ok := x.GetValue().(*QueryArg_TimestampValue); ok {
+		return x.TimestampValue
+	}
+	return nil
+}
+
+type isQueryArg_Value interface {
+	isQueryArg_Value()
+}
+
+type QueryArg_NullValue struct {
+	// NULL của SQL (giá trị của field không quan trọng)
+	NullValue bool `protobuf:"varint,1,opt,name=null_value,json=nullValue,proto3,oneof"`
+}
+
+type QueryArg_StringValue struct {
+	StringValue string `protobuf:"bytes,2,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type QueryArg_IntValue struct {
+	IntValue int64 `protobuf:"varint,3,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type QueryArg_DoubleValue struct {
+	DoubleValue float64 `protobuf:"fixed64,4,opt,name=double_value,json=doubleValue,proto3,oneof"`
+}
+
+type QueryArg_BoolValue struct {
+	BoolValue bool `protobuf:"varint,5,opt,name=bool_value,json=boolValue,proto3,oneof"`
+}
+
+type QueryArg_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,6,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
+type QueryArg_TimestampValue struct {
+	TimestampValue *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp_value,json=timestampValue,proto3,oneof"`
+}
+
+func (*QueryArg_NullValue) isQueryArg_Value() {}
+
+func (*QueryArg_StringValue) isQueryArg_Value() {}
+
+func (*QueryArg_IntValue) isQueryArg_Value() {}
+
+func (*QueryArg_DoubleValue) isQueryArg_Value() {}
+
+func (*QueryArg_BoolValue) isQueryArg_Value() {}
+
+func (*QueryArg_BytesValue) isQueryArg_Value() {}
+
+func (*QueryArg_TimestampValue) isQueryArg_Value() {}
+
 type TestHTTP3Response struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -300,12 +591,14 @@ type TestHTTP3Response struct {
 	QueryId      string             `protobuf:"bytes,2,opt,name=query_id,json=queryId,proto3" json:"query_id,omitempty"`
 	Records      []*structpb.Struct `protobuf:"bytes,4,rep,name=records,proto3" json:"records,omitempty"`
 	ReceivedSize int32              `protobuf:"varint,3,opt,name=received_size,json=receivedSize,proto3" json:"received_size,omitempty"`
+	// true khi kết quả bị cắt ở max_rows của query trong registry.
+	Truncated bool `protobuf:"varint,5,opt,name=truncated,proto3" json:"truncated,omitempty"`
 }
 
 func (x *TestHTTP3Response) Reset() {
 	*x = TestHTTP3Response{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_laminar_proto_msgTypes[4]
+		mi := &file_api_proto_laminar_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -318,7 +611,7 @@ func (x *TestHTTP3Response) String() string {
 func (*TestHTTP3Response) ProtoMessage() {}
 
 func (x *TestHTTP3Response) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_laminar_proto_msgTypes[4]
+	mi := &file_api_proto_laminar_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -331,7 +624,7 @@ func (x *TestHTTP3Response) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TestHTTP3Response.ProtoReflect.Descriptor instead.
 func (*TestHTTP3Response) Descriptor() ([]byte, []int) {
-	return file_api_proto_laminar_proto_rawDescGZIP(), []int{4}
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *TestHTTP3Response) GetStatus() string {
@@ -362,6 +655,13 @@ func (x *TestHTTP3Response) GetReceivedSize() int32 {
 	return 0
 }
 
+func (x *TestHTTP3Response) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
 type PingRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -373,7 +673,7 @@ type PingRequest struct {
 func (x *PingRequest) Reset() {
 	*x = PingRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_laminar_proto_msgTypes[5]
+		mi := &file_api_proto_laminar_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -386,7 +686,7 @@ func (x *PingRequest) String() string {
 func (*PingRequest) ProtoMessage() {}
 
 func (x *PingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_laminar_proto_msgTypes[5]
+	mi := &file_api_proto_laminar_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -399,7 +699,7 @@ func (x *PingRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
 func (*PingRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_laminar_proto_rawDescGZIP(), []int{5}
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *PingRequest) GetMessage() string {
@@ -420,7 +720,7 @@ type PingResponse struct {
 func (x *PingResponse) Reset() {
 	*x = PingResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_proto_laminar_proto_msgTypes[6]
+		mi := &file_api_proto_laminar_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -433,7 +733,7 @@ func (x *PingResponse) String() string {
 func (*PingResponse) ProtoMessage() {}
 
 func (x *PingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_laminar_proto_msgTypes[6]
+	mi := &file_api_proto_laminar_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -446,7 +746,7 @@ func (x *PingResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
 func (*PingResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_laminar_proto_rawDescGZIP(), []int{6}
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PingResponse) GetMessage() string {
@@ -456,6 +756,326 @@ func (x *PingResponse) GetMessage() string {
 	return ""
 }
 
+type ResizeShardsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Số shard mới (>= 1)
+	Shards int32 `protobuf:"varint,1,opt,name=shards,proto3" json:"shards,omitempty"`
+}
+
+func (x *ResizeShardsRequest) Reset() {
+	*x = ResizeShardsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_laminar_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResizeShardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResizeShardsRequest) ProtoMessage() {}
+
+func (x *ResizeShardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_laminar_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResizeShardsRequest.ProtoReflect.Descriptor instead.
+func (*ResizeShardsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ResizeShardsRequest) GetShards() int32 {
+	if x != nil {
+		return x.Shards
+	}
+	return 0
+}
+
+type ResizeShardsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PreviousShards int32 `protobuf:"varint,1,opt,name=previous_shards,json=previousShards,proto3" json:"previous_shards,omitempty"`
+	Shards         int32 `protobuf:"varint,2,opt,name=shards,proto3" json:"shards,omitempty"`
+}
+
+func (x *ResizeShardsResponse) Reset() {
+	*x = ResizeShardsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_laminar_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResizeShardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResizeShardsResponse) ProtoMessage() {}
+
+func (x *ResizeShardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_laminar_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResizeShardsResponse.ProtoReflect.Descriptor instead.
+func (*ResizeShardsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResizeShardsResponse) GetPreviousShards() int32 {
+	if x != nil {
+		return x.PreviousShards
+	}
+	return 0
+}
+
+func (x *ResizeShardsResponse) GetShards() int32 {
+	if x != nil {
+		return x.Shards
+	}
+	return 0
+}
+
+// Tham số worker đổi được lúc runtime.
+type Tuning struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HighWaterMark int32 `protobuf:"varint,1,opt,name=high_water_mark,json=highWaterMark,proto3" json:"high_water_mark,omitempty"`
+	LowWaterMark  int32 `protobuf:"varint,2,opt,name=low_water_mark,json=lowWaterMark,proto3" json:"low_water_mark,omitempty"`
+	// Inbox dài hơn thì shard không nhận job mới
+	MaxInboxDepth int32 `protobuf:"varint,3,opt,name=max_inbox_depth,json=maxInboxDepth,proto3" json:"max_inbox_depth,omitempty"`
+	// <= 0 tắt work stealing
+	StealThreshold int32 `protobuf:"varint,4,opt,name=steal_threshold,json=stealThreshold,proto3" json:"steal_threshold,omitempty"`
+	// 0 tắt việc drop job chờ quá lâu
+	MaxQueueAge *durationpb.Duration `protobuf:"bytes,5,opt,name=max_queue_age,json=maxQueueAge,proto3" json:"max_queue_age,omitempty"`
+}
+
+func (x *Tuning) Reset() {
+	*x = Tuning{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_laminar_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Tuning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tuning) ProtoMessage() {}
+
+func (x *Tuning) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_laminar_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tuning.ProtoReflect.Descriptor instead.
+func (*Tuning) Descriptor() ([]byte, []int) {
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Tuning) GetHighWaterMark() int32 {
+	if x != nil {
+		return x.HighWaterMark
+	}
+	return 0
+}
+
+func (x *Tuning) GetLowWaterMark() int32 {
+	if x != nil {
+		return x.LowWaterMark
+	}
+	return 0
+}
+
+func (x *Tuning) GetMaxInboxDepth() int32 {
+	if x != nil {
+		return x.MaxInboxDepth
+	}
+	return 0
+}
+
+func (x *Tuning) GetStealThreshold() int32 {
+	if x != nil {
+		return x.StealThreshold
+	}
+	return 0
+}
+
+func (x *Tuning) GetMaxQueueAge() *durationpb.Duration {
+	if x != nil {
+		return x.MaxQueueAge
+	}
+	return nil
+}
+
+type UpdateTuningRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HighWaterMark  *int32               `protobuf:"varint,1,opt,name=high_water_mark,json=highWaterMark,proto3,oneof" json:"high_water_mark,omitempty"`
+	LowWaterMark   *int32               `protobuf:"varint,2,opt,name=low_water_mark,json=lowWaterMark,proto3,oneof" json:"low_water_mark,omitempty"`
+	MaxInboxDepth  *int32               `protobuf:"varint,3,opt,name=max_inbox_depth,json=maxInboxDepth,proto3,oneof" json:"max_inbox_depth,omitempty"`
+	StealThreshold *int32               `protobuf:"varint,4,opt,name=steal_threshold,json=stealThreshold,proto3,oneof" json:"steal_threshold,omitempty"`
+	MaxQueueAge    *durationpb.Duration `protobuf:"bytes,5,opt,name=max_queue_age,json=maxQueueAge,proto3" json:"max_queue_age,omitempty"`
+}
+
+func (x *UpdateTuningRequest) Reset() {
+	*x = UpdateTuningRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_laminar_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateTuningRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTuningRequest) ProtoMessage() {}
+
+func (x *UpdateTuningRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_laminar_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTuningRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTuningRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateTuningRequest) GetHighWaterMark() int32 {
+	if x != nil && x.HighWaterMark != nil {
+		return *x.HighWaterMark
+	}
+	return 0
+}
+
+func (x *UpdateTuningRequest) GetLowWaterMark() int32 {
+	if x != nil && x.LowWaterMark != nil {
+		return *x.LowWaterMark
+	}
+	return 0
+}
+
+func (x *UpdateTuningRequest) GetMaxInboxDepth() int32 {
+	if x != nil && x.MaxInboxDepth != nil {
+		return *x.MaxInboxDepth
+	}
+	return 0
+}
+
+func (x *UpdateTuningRequest) GetStealThreshold() int32 {
+	if x != nil && x.StealThreshold != nil {
+		return *x.StealThreshold
+	}
+	return 0
+}
+
+func (x *UpdateTuningRequest) GetMaxQueueAge() *durationpb.Duration {
+	if x != nil {
+		return x.MaxQueueAge
+	}
+	return nil
+}
+
+type UpdateTuningResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Previous *Tuning `protobuf:"bytes,1,opt,name=previous,proto3" json:"previous,omitempty"`
+	Current  *Tuning `protobuf:"bytes,2,opt,name=current,proto3" json:"current,omitempty"`
+}
+
+func (x *UpdateTuningResponse) Reset() {
+	*x = UpdateTuningResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_laminar_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateTuningResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTuningResponse) ProtoMessage() {}
+
+func (x *UpdateTuningResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_laminar_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTuningResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTuningResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_laminar_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateTuningResponse) GetPrevious() *Tuning {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *UpdateTuningResponse) GetCurrent() *Tuning {
+	if x != nil {
+		return x.Current
+	}
+	return nil
+}
+
 var File_api_proto_laminar_proto protoreflect.FileDescriptor
 
 var file_api_proto_laminar_proto_rawDesc = []byte{
@@ -463,53 +1083,153 @@ var file_api_proto_laminar_proto_rawDesc = []byte{
 	0x6e, 0x61, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x6c, 0x61, 0x6d, 0x69, 0x6e,
 	0x61, 0x72, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x22, 0xb1, 0x01, 0x0a, 0x0b, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12,
-	0x33, 0x0a, 0x16, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x77, 0x6f, 0x72,
-	0x6b, 0x5f, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x13, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x4c, 0x6f,
-	0x61, 0x64, 0x4d, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x1a,
-	0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
-	0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x64,
-	0x64, 0x69, 0x6e, 0x67, 0x22, 0xa9, 0x01, 0x0a, 0x0c, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x39,
-	0x0a, 0x19, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
-	0x69, 0x6e, 0x67, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x16, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
-	0x69, 0x6e, 0x67, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x73,
-	0x75, 0x6c, 0x74, 0x5f, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
-	0x22, 0x29, 0x0a, 0x11, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
-	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x22, 0x62, 0x0a, 0x10, 0x54,
-	0x65, 0x73, 0x74, 0x48, 0x54, 0x54, 0x50, 0x33, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x18, 0x0a, 0x07, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x51, 0x75, 0x65, 0x72, 0x79, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x51, 0x75, 0x65,
-	0x72, 0x79, 0x53, 0x51, 0x4c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x51, 0x75, 0x65,
-	0x72, 0x79, 0x53, 0x51, 0x4c, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22,
-	0x9e, 0x01, 0x0a, 0x11, 0x54, 0x65, 0x73, 0x74, 0x48, 0x54, 0x54, 0x50, 0x33, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x19, 0x0a,
-	0x08, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x71, 0x75, 0x65, 0x72, 0x79, 0x49, 0x64, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75,
-	0x63, 0x74, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72,
-	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x53, 0x69, 0x7a, 0x65,
-	0x22, 0x27, 0x0a, 0x0b, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x28, 0x0a, 0x0c, 0x50, 0x69, 0x6e,
-	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x32, 0xd9, 0x02, 0x0a, 0x0e, 0x4c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x47,
+	0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x22, 0xd8, 0x01, 0x0a, 0x0b, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
+	0x12, 0x33, 0x0a, 0x16, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x77, 0x6f,
+	0x72, 0x6b, 0x5f, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x13, 0x73, 0x69, 0x6d, 0x75, 0x6c, 0x61, 0x74, 0x65, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x4c,
+	0x6f, 0x61, 0x64, 0x4d, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x61, 0x64, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61,
+	0x64, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x25, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x2e, 0x57, 0x6f,
+	0x72, 0x6b, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x22, 0xdc, 0x02, 0x0a,
+	0x0c, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x39, 0x0a, 0x19, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x5f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x5f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x16, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x54, 0x69, 0x6d, 0x65, 0x4e,
+	0x73, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x70, 0x61, 0x79, 0x6c,
+	0x6f, 0x61, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x2b, 0x0a, 0x12, 0x71, 0x75, 0x65, 0x75,
+	0x65, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x73, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x57, 0x61, 0x69, 0x74, 0x54,
+	0x69, 0x6d, 0x65, 0x4e, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x73, 0x12, 0x23, 0x0a,
+	0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x29, 0x0a, 0x11, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x22, 0xcf, 0x01, 0x0a, 0x10, 0x54, 0x65, 0x73, 0x74, 0x48,
+	0x54, 0x54, 0x50, 0x33, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x51,
+	0x4c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x51, 0x75, 0x65, 0x72, 0x79, 0x53, 0x51,
+	0x4c, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x71,
+	0x75, 0x65, 0x72, 0x79, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x71, 0x75, 0x65, 0x72, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x71, 0x75,
+	0x65, 0x72, 0x79, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x71, 0x75, 0x65, 0x72, 0x79, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x12, 0x25, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x41,
+	0x72, 0x67, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x22, 0xa8, 0x02, 0x0a, 0x08, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x41, 0x72, 0x67, 0x12, 0x1f, 0x0a, 0x0a, 0x6e, 0x75, 0x6c, 0x6c, 0x5f, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09, 0x6e, 0x75, 0x6c,
+	0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0c, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b,
+	0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a, 0x09, 0x69,
+	0x6e, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00,
+	0x52, 0x08, 0x69, 0x6e, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0c, 0x64, 0x6f,
+	0x75, 0x62, 0x6c, 0x65, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x00, 0x52, 0x0b, 0x64, 0x6f, 0x75, 0x62, 0x6c, 0x65, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x1f, 0x0a, 0x0a, 0x62, 0x6f, 0x6f, 0x6c, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09, 0x62, 0x6f, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x12, 0x21, 0x0a, 0x0b, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x0a, 0x62, 0x79, 0x74, 0x65, 0x73, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x45, 0x0a, 0x0f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x48, 0x00, 0x52, 0x0e, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x22, 0xbc, 0x01, 0x0a, 0x11, 0x54, 0x65, 0x73, 0x74, 0x48, 0x54, 0x54, 0x50,
+	0x33, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x19, 0x0a, 0x08, 0x71, 0x75, 0x65, 0x72, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x71, 0x75, 0x65, 0x72, 0x79, 0x49, 0x64, 0x12, 0x31, 0x0a, 0x07,
+	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12,
+	0x23, 0x0a, 0x0d, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
+	0x53, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x74, 0x72, 0x75, 0x6e, 0x63, 0x61, 0x74,
+	0x65, 0x64, 0x22, 0x27, 0x0a, 0x0b, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x28, 0x0a, 0x0c, 0x50,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x2d, 0x0a, 0x13, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x53,
+	0x68, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x68,
+	0x61, 0x72, 0x64, 0x73, 0x22, 0x57, 0x0a, 0x14, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x53, 0x68,
+	0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x0f,
+	0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53,
+	0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x22, 0xe6, 0x01,
+	0x0a, 0x06, 0x54, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x26, 0x0a, 0x0f, 0x68, 0x69, 0x67, 0x68,
+	0x5f, 0x77, 0x61, 0x74, 0x65, 0x72, 0x5f, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x68, 0x69, 0x67, 0x68, 0x57, 0x61, 0x74, 0x65, 0x72, 0x4d, 0x61, 0x72, 0x6b,
+	0x12, 0x24, 0x0a, 0x0e, 0x6c, 0x6f, 0x77, 0x5f, 0x77, 0x61, 0x74, 0x65, 0x72, 0x5f, 0x6d, 0x61,
+	0x72, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6c, 0x6f, 0x77, 0x57, 0x61, 0x74,
+	0x65, 0x72, 0x4d, 0x61, 0x72, 0x6b, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x6e,
+	0x62, 0x6f, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0d, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x62, 0x6f, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x27,
+	0x0a, 0x0f, 0x73, 0x74, 0x65, 0x61, 0x6c, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x73, 0x74, 0x65, 0x61, 0x6c, 0x54, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x3d, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x5f, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x41, 0x67, 0x65, 0x22, 0xd6, 0x02, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x54, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2b,
+	0x0a, 0x0f, 0x68, 0x69, 0x67, 0x68, 0x5f, 0x77, 0x61, 0x74, 0x65, 0x72, 0x5f, 0x6d, 0x61, 0x72,
+	0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x0d, 0x68, 0x69, 0x67, 0x68, 0x57,
+	0x61, 0x74, 0x65, 0x72, 0x4d, 0x61, 0x72, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0e, 0x6c,
+	0x6f, 0x77, 0x5f, 0x77, 0x61, 0x74, 0x65, 0x72, 0x5f, 0x6d, 0x61, 0x72, 0x6b, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x0c, 0x6c, 0x6f, 0x77, 0x57, 0x61, 0x74, 0x65, 0x72, 0x4d,
+	0x61, 0x72, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x2b, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x6e,
+	0x62, 0x6f, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48,
+	0x02, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x62, 0x6f, 0x78, 0x44, 0x65, 0x70, 0x74, 0x68,
+	0x88, 0x01, 0x01, 0x12, 0x2c, 0x0a, 0x0f, 0x73, 0x74, 0x65, 0x61, 0x6c, 0x5f, 0x74, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52, 0x0e,
+	0x73, 0x74, 0x65, 0x61, 0x6c, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x88, 0x01,
+	0x01, 0x12, 0x3d, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x61,
+	0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x51, 0x75, 0x65, 0x75, 0x65, 0x41, 0x67, 0x65,
+	0x42, 0x12, 0x0a, 0x10, 0x5f, 0x68, 0x69, 0x67, 0x68, 0x5f, 0x77, 0x61, 0x74, 0x65, 0x72, 0x5f,
+	0x6d, 0x61, 0x72, 0x6b, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x6c, 0x6f, 0x77, 0x5f, 0x77, 0x61, 0x74,
+	0x65, 0x72, 0x5f, 0x6d, 0x61, 0x72, 0x6b, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x6d, 0x61, 0x78, 0x5f,
+	0x69, 0x6e, 0x62, 0x6f, 0x78, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x42, 0x12, 0x0a, 0x10, 0x5f,
+	0x73, 0x74, 0x65, 0x61, 0x6c, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x22,
+	0x6e, 0x0a, 0x14, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x76, 0x69,
+	0x6f, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6c, 0x61, 0x6d, 0x69,
+	0x6e, 0x61, 0x72, 0x2e, 0x54, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x70, 0x72, 0x65, 0x76,
+	0x69, 0x6f, 0x75, 0x73, 0x12, 0x29, 0x0a, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x2e,
+	0x54, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x2a,
+	0x32, 0x0a, 0x08, 0x57, 0x6f, 0x72, 0x6b, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x13, 0x0a, 0x0f, 0x57,
+	0x4f, 0x52, 0x4b, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x53, 0x4c, 0x45, 0x45, 0x50, 0x10, 0x00,
+	0x12, 0x11, 0x0a, 0x0d, 0x57, 0x4f, 0x52, 0x4b, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x43, 0x50,
+	0x55, 0x10, 0x01, 0x32, 0xf3, 0x03, 0x0a, 0x0e, 0x4c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x47,
 	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x12, 0x3c, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73,
 	0x73, 0x53, 0x69, 0x6e, 0x67, 0x6c, 0x65, 0x12, 0x14, 0x2e, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x61,
 	0x72, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
@@ -530,8 +1250,18 @@ var file_api_proto_laminar_proto_rawDesc = []byte{
 	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x08, 0x50, 0x69, 0x6e, 0x67, 0x50, 0x6f,
 	0x6e, 0x67, 0x12, 0x14, 0x2e, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x2e, 0x50, 0x69, 0x6e,
 	0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x6c, 0x61, 0x6d, 0x69, 0x6e,
-	0x61, 0x72, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
-	0x06, 0x5a, 0x04, 0x2e, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x61, 0x72, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x4b, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12,
+	0x1c, 0x2e, 0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65,
+	0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e,
+	0x6c, 0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x53, 0x68,
+	0x61, 0x72, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4b, 0x0a, 0x0c,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x75, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x2e, 0x6c,
+	0x61, 0x6d, 0x69, 0x6e, 0x61, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x75, 0x6e,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6c, 0x61, 0x6d,
+	0x69, 0x6e, 0x61, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x75, 0x6e, 0x69, 0x6e,
+	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x2f, 0x70,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -546,34 +1276,55 @@ func file_api_proto_laminar_proto_rawDescGZIP() []byte {
 	return file_api_proto_laminar_proto_rawDescData
 }
 
-var file_api_proto_laminar_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_api_proto_laminar_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_laminar_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_api_proto_laminar_proto_goTypes = []any{
-	(*WorkRequest)(nil),       // 0: laminar.WorkRequest
-	(*WorkResponse)(nil),      // 1: laminar.WorkResponse
-	(*EventSubscription)(nil), // 2: laminar.EventSubscription
-	(*TestHTTP3Request)(nil),  // 3: laminar.TestHTTP3Request
-	(*TestHTTP3Response)(nil), // 4: laminar.TestHTTP3Response
-	(*PingRequest)(nil),       // 5: laminar.PingRequest
-	(*PingResponse)(nil),      // 6: laminar.PingResponse
-	(*structpb.Struct)(nil),   // 7: google.protobuf.Struct
+	(WorkKind)(0),                 // 0: laminar.WorkKind
+	(*WorkRequest)(nil),           // 1: laminar.WorkRequest
+	(*WorkResponse)(nil),          // 2: laminar.WorkResponse
+	(*EventSubscription)(nil),     // 3: laminar.EventSubscription
+	(*TestHTTP3Request)(nil),      // 4: laminar.TestHTTP3Request
+	(*QueryArg)(nil),              // 5: laminar.QueryArg
+	(*TestHTTP3Response)(nil),     // 6: laminar.TestHTTP3Response
+	(*PingRequest)(nil),           // 7: laminar.PingRequest
+	(*PingResponse)(nil),          // 8: laminar.PingResponse
+	(*ResizeShardsRequest)(nil),   // 9: laminar.ResizeShardsRequest
+	(*ResizeShardsResponse)(nil),  // 10: laminar.ResizeShardsResponse
+	(*Tuning)(nil),                // 11: laminar.Tuning
+	(*UpdateTuningRequest)(nil),   // 12: laminar.UpdateTuningRequest
+	(*UpdateTuningResponse)(nil),  // 13: laminar.UpdateTuningResponse
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 15: google.protobuf.Struct
+	(*durationpb.Duration)(nil),   // 16: google.protobuf.Duration
 }
 var file_api_proto_laminar_proto_depIdxs = []int32{
-	7, // 0: laminar.TestHTTP3Response.records:type_name -> google.protobuf.Struct
-	0, // 1: laminar.LaminarGateway.ProcessSingle:input_type -> laminar.WorkRequest
-	2, // 2: laminar.LaminarGateway.SubscribeToEvents:input_type -> laminar.EventSubscription
-	0, // 3: laminar.LaminarGateway.PipelineProcess:input_type -> laminar.WorkRequest
-	3, // 4: laminar.LaminarGateway.TestHTTP3:input_type -> laminar.TestHTTP3Request
-	5, // 5: laminar.LaminarGateway.PingPong:input_type -> laminar.PingRequest
-	1, // 6: laminar.LaminarGateway.ProcessSingle:output_type -> laminar.WorkResponse
-	1, // 7: laminar.LaminarGateway.SubscribeToEvents:output_type -> laminar.WorkResponse
-	1, // 8: laminar.LaminarGateway.PipelineProcess:output_type -> laminar.WorkResponse
-	4, // 9: laminar.LaminarGateway.TestHTTP3:output_type -> laminar.TestHTTP3Response
-	6, // 10: laminar.LaminarGateway.PingPong:output_type -> laminar.PingResponse
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: laminar.WorkRequest.kind:type_name -> laminar.WorkKind
+	5,  // 1: laminar.TestHTTP3Request.args:type_name -> laminar.QueryArg
+	14, // 2: laminar.QueryArg.timestamp_value:type_name -> google.protobuf.Timestamp
+	15, // 3: laminar.TestHTTP3Response.records:type_name -> google.protobuf.Struct
+	16, // 4: laminar.Tuning.max_queue_age:type_name -> google.protobuf.Duration
+	16, // 5: laminar.UpdateTuningRequest.max_queue_age:type_name -> google.protobuf.Duration
+	11, // 6: laminar.UpdateTuningResponse.previous:type_name -> laminar.Tuning
+	11, // 7: laminar.UpdateTuningResponse.current:type_name -> laminar.Tuning
+	1,  // 8: laminar.LaminarGateway.ProcessSingle:input_type -> laminar.WorkRequest
+	3,  // 9: laminar.LaminarGateway.SubscribeToEvents:input_type -> laminar.EventSubscription
+	1,  // 10: laminar.LaminarGateway.PipelineProcess:input_type -> laminar.WorkRequest
+	4,  // 11: laminar.LaminarGateway.TestHTTP3:input_type -> laminar.TestHTTP3Request
+	7,  // 12: laminar.LaminarGateway.PingPong:input_type -> laminar.PingRequest
+	9,  // 13: laminar.LaminarGateway.ResizeShards:input_type -> laminar.ResizeShardsRequest
+	12, // 14: laminar.LaminarGateway.UpdateTuning:input_type -> laminar.UpdateTuningRequest
+	2,  // 15: laminar.LaminarGateway.ProcessSingle:output_type -> laminar.WorkResponse
+	2,  // 16: laminar.LaminarGateway.SubscribeToEvents:output_type -> laminar.WorkResponse
+	2,  // 17: laminar.LaminarGateway.PipelineProcess:output_type -> laminar.WorkResponse
+	6,  // 18: laminar.LaminarGateway.TestHTTP3:output_type -> laminar.TestHTTP3Response
+	8,  // 19: laminar.LaminarGateway.PingPong:output_type -> laminar.PingResponse
+	10, // 20: laminar.LaminarGateway.ResizeShards:output_type -> laminar.ResizeShardsResponse
+	13, // 21: laminar.LaminarGateway.UpdateTuning:output_type -> laminar.UpdateTuningResponse
+	15, // [15:22] is the sub-list for method output_type
+	8,  // [8:15] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_laminar_proto_init() }
@@ -631,7 +1382,7 @@ func file_api_proto_laminar_proto_init() {
 			}
 		}
 		file_api_proto_laminar_proto_msgTypes[4].Exporter = func(v any, i int) any {
-			switch v := v.(*TestHTTP3Response); i {
+			switch v := v.(*QueryArg); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -643,7 +1394,7 @@ func file_api_proto_laminar_proto_init() {
 			}
 		}
 		file_api_proto_laminar_proto_msgTypes[5].Exporter = func(v any, i int) any {
-			switch v := v.(*PingRequest); i {
+			switch v := v.(*TestHTTP3Response); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -655,6 +1406,18 @@ func file_api_proto_laminar_proto_init() {
 			}
 		}
 		file_api_proto_laminar_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*PingRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_laminar_proto_msgTypes[7].Exporter = func(v any, i int) any {
 			switch v := v.(*PingResponse); i {
 			case 0:
 				return &v.state
@@ -666,19 +1429,90 @@ func file_api_proto_laminar_proto_init() {
 				return nil
 			}
 		}
+		file_api_proto_laminar_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*ResizeShardsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_laminar_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ResizeShardsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_laminar_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*Tuning); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_laminar_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdateTuningRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_laminar_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdateTuningResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_api_proto_laminar_proto_msgTypes[4].OneofWrappers = []any{
+		(*QueryArg_NullValue)(nil),
+		(*QueryArg_StringValue)(nil),
+		(*QueryArg_IntValue)(nil),
+		(*QueryArg_DoubleValue)(nil),
+		(*QueryArg_BoolValue)(nil),
+		(*QueryArg_BytesValue)(nil),
+		(*QueryArg_TimestampValue)(nil),
 	}
+	file_api_proto_laminar_proto_msgTypes[11].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_proto_laminar_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_proto_laminar_proto_goTypes,
 		DependencyIndexes: file_api_proto_laminar_proto_depIdxs,
+		EnumInfos:         file_api_proto_laminar_proto_enumTypes,
 		MessageInfos:      file_api_proto_laminar_proto_msgTypes,
 	}.Build()
 	File_api_proto_laminar_proto = out.File